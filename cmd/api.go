@@ -8,12 +8,18 @@
 package main
 
 import (
+	"time"
+
+	"go-fiber-starter/internal/api/admin"
 	"go-fiber-starter/internal/api/auth"
+	"go-fiber-starter/internal/api/oauth"
+	"go-fiber-starter/internal/api/org"
 	"go-fiber-starter/internal/middleware"
+	"go-fiber-starter/internal/service"
 	"go-fiber-starter/pkg/config"
 	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/validate"
 
-	jwtware "github.com/gofiber/contrib/v3/jwt"
 	swaggo "github.com/gofiber/contrib/v3/swaggo"
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
@@ -22,9 +28,18 @@ import (
 )
 
 func api() {
+	if err := service.LoadRevocations(); err != nil {
+		logger.Fatal("加载token吊销列表失败: %v", err)
+	}
+	service.StartRevocationCleanup(time.Hour)
+	service.InitLoginProtection()
+	service.StartLoginProtectionCleanup(time.Hour)
+	service.StartAuditCleanup(24 * time.Hour)
+
 	// 创建Fiber应用
 	app := fiber.New(fiber.Config{
-		ErrorHandler: middleware.ErrorHandler,
+		ErrorHandler:    middleware.ErrorHandler,
+		StructValidator: validate.Default,
 	})
 
 	app.Get("/swagger/*", swaggo.HandlerDefault)
@@ -37,21 +52,15 @@ func api() {
 	}))
 
 	auth.RegisterUnProtectedRoutes(app)
+	oauth.RegisterRoutes(app)
 	// 配置路由组
 	api := app.Group("/api")
-	api.Use(jwtware.New(jwtware.Config{
-		SigningKey: jwtware.SigningKey{Key: []byte(config.Current.Jwt.Secret)},
-		// 添加自定义错误处理，返回401状态码
-		ErrorHandler: func(c fiber.Ctx, err error) error {
-			logger.Error("JWT验证失败: %v", err)
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"code":    fiber.StatusUnauthorized,
-				"message": "认证失败，请先登录",
-			})
-		},
-	}))
+	api.Use(middleware.Auth())
+	api.Use(middleware.Tenant())
 
 	auth.RegisterRoutes(api)
+	admin.RegisterRoutes(api)
+	org.RegisterRoutes(api)
 
 	if err := app.Listen(":" + config.Current.App.Port); err != nil {
 		logger.Fatal("启动服务器失败: %v", err)
@@ -16,7 +16,12 @@ import (
 	_ "go-fiber-starter/docs"
 	"go-fiber-starter/pkg/config"
 	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/i18n"
+	"go-fiber-starter/pkg/jwtkey"
 	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/mailer"
+	"go-fiber-starter/pkg/oidc"
+	"go-fiber-starter/pkg/password"
 )
 
 func main() {
@@ -28,9 +33,29 @@ func main() {
 		logger.Fatal("加载配置失败: %v", err)
 	}
 
+	if err := jwtkey.Init(); err != nil {
+		logger.Fatal("加载JWT密钥失败: %v", err)
+	}
+
 	if err := db.Init(); err != nil {
 		logger.Fatal("初始化数据库失败: %v", err)
 	}
 
+	if err := mailer.Init(); err != nil {
+		logger.Fatal("初始化邮件发送失败: %v", err)
+	}
+
+	if err := oidc.Init(); err != nil {
+		logger.Fatal("加载OIDC配置失败: %v", err)
+	}
+
+	if err := password.Init(); err != nil {
+		logger.Fatal("加载密码策略失败: %v", err)
+	}
+
+	if err := i18n.Init(); err != nil {
+		logger.Fatal("加载语言包失败: %v", err)
+	}
+
 	api()
 }
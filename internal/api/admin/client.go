@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ListClients 查询未吊销的机器客户端，不包含密钥
+func ListClients(c fiber.Ctx) error {
+	clients, err := db.ListOAuthClients()
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, clients)
+}
+
+// CreateClient 注册机器客户端，client_secret 明文只在本次响应中返回
+func CreateClient(c fiber.Ctx) error {
+	var req CreateClientRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+
+	current, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	created, err := service.CreateClient(current, req.Name, req.Scopes)
+	if err != nil {
+		if errors.Is(err, service.ErrClientScope) {
+			return errScopeInvalid
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{
+		Action:     model.AuditAdminClientAdd,
+		TargetType: "client",
+		TargetId:   created.Client.ClientId,
+		TargetName: created.Client.Name,
+		Detail:     "scopes=" + strings.Join(created.Client.Scopes, ","),
+	})
+	return response.Success(c, created)
+}
+
+// RevokeClient 吊销机器客户端，已签发的 token 立即失效
+func RevokeClient(c fiber.Ctx) error {
+	client, err := service.RevokeClient(c.Params("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrClientInvalid) {
+			return errClientNotFound
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{
+		Action:     model.AuditAdminClientDel,
+		TargetType: "client",
+		TargetId:   client.ClientId,
+		TargetName: client.Name,
+	})
+	return response.Success(c, nil)
+}
@@ -0,0 +1,37 @@
+package admin
+
+import "time"
+
+// UpdateUserRequest 修改用户，未传的字段保持不变；roles 为完整的角色列表，会替换用户现有的角色
+type UpdateUserRequest struct {
+	Username *string   `json:"username" validate:"max=32" label:"field.username"`
+	Roles    *[]string `json:"roles"`
+}
+
+// ResetUserPasswordRequest 管理员为用户设置新密码，密码强度由密码策略单独校验
+type ResetUserPasswordRequest struct {
+	Password string `json:"password" validate:"required" label:"field.password"`
+}
+
+// UpdateRoleRequest 修改角色设置，未传的字段保持不变
+type UpdateRoleRequest struct {
+	RequireMfa *bool `json:"requireMfa"`
+}
+
+// CreateInviteRequest 创建邀请码，role 为注册后额外授予的角色，expiresAt 为空时不过期
+type CreateInviteRequest struct {
+	Role      string     `json:"role" validate:"max=64" label:"field.role"`
+	Note      string     `json:"note" validate:"max=255" label:"field.note"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// ImpersonateRequest 模拟登录，reason 会写入审计日志
+type ImpersonateRequest struct {
+	Reason string `json:"reason" validate:"max=255" label:"field.reason"`
+}
+
+// CreateClientRequest 注册机器客户端，scopes 只能是当前管理员拥有的权限
+type CreateClientRequest struct {
+	Name   string   `json:"name" validate:"required,max=64" label:"field.name"`
+	Scopes []string `json:"scopes"`
+}
@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"errors"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// 管理接口返回的应用错误，错误码对外稳定，客户端可以据此区分失败原因；提示为语言包中的 key
+var (
+	errBadRequest   = apperror.Validation(apperror.CodeBadRequest, "error.bad_request")
+	errUserNotFound = apperror.NotFound("user_not_found", "auth.user_not_found")
+	// errRoleManageRequired 修改角色等同于授予权限，只有 user:write 的管理员不能给任何人（包括自己）分配角色；
+	// 带角色的邀请码注册后同样会授予该角色，也需要 role:manage
+	errRoleManageRequired = apperror.Forbidden("role_manage_required", "admin.role_manage_required")
+	errRoleNotFound       = apperror.NotFound("role_not_found", "admin.role_not_found")
+	// errRoleInvalid 请求体中指定的角色不存在
+	errRoleInvalid          = apperror.Validation("role_invalid", "admin.role_not_found")
+	errUsernameRequired     = apperror.Validation("username_required", "admin.username_required")
+	errUsernameTaken        = apperror.Conflict("username_taken", "auth.username_taken")
+	errCannotDisableSelf    = apperror.Validation("cannot_disable_self", "admin.cannot_disable_self")
+	errCannotDeleteSelf     = apperror.Validation("cannot_delete_self", "admin.cannot_delete_self")
+	errUserNotPending       = apperror.Validation("user_not_pending", "admin.user_not_pending")
+	errPasswordPolicy       = apperror.Validation("password_policy", "auth.password_policy")
+	errScopeInvalid         = apperror.Validation("scope_invalid", "error.scope_invalid")
+	errClientNotFound       = apperror.NotFound("client_not_found", "admin.client_not_found")
+	errExpiresPast          = apperror.Validation("expires_in_past", "error.expires_in_past")
+	errInviteNotFound       = apperror.NotFound("invite_not_found", "admin.invite_not_found")
+	errAuditTimeInvalid     = apperror.Validation("invalid_time", "admin.invalid_time")
+	errImpersonateSelf      = apperror.Validation("impersonate_self", "admin.impersonate_self")
+	errImpersonateInactive  = apperror.Validation("impersonate_inactive", "admin.impersonate_inactive")
+	errImpersonateProtected = apperror.Forbidden("impersonate_protected", "admin.impersonate_protected")
+)
+
+// userLookupError 用户不存在时返回 404，其余错误交给 ErrorHandler 按服务端错误处理
+func userLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errUserNotFound.Wrap(err)
+	}
+	return apperror.Internal(err)
+}
+
+// currentUser 读取当前登录的管理员，读取失败时按 userLookupError 处理
+func currentUser(c fiber.Ctx) (*model.User, error) {
+	user, err := service.CurrentUser(c)
+	if err != nil {
+		return nil, currentUserError(err)
+	}
+	return user, nil
+}
+
+// currentActor 读取实际发起请求的管理员，模拟登录时为管理员本人
+func currentActor(c fiber.Ctx) (*model.User, error) {
+	user, err := service.CurrentActor(c)
+	if err != nil {
+		return nil, currentUserError(err)
+	}
+	return user, nil
+}
+
+func currentUserError(err error) error {
+	if errors.Is(err, service.ErrUserDisabled) || errors.Is(err, service.ErrNotUser) {
+		return errUserNotFound.Wrap(err)
+	}
+	return userLookupError(err)
+}
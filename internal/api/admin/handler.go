@@ -0,0 +1,301 @@
+package admin
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/password"
+	"go-fiber-starter/pkg/query"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var hashPassword = password.Hash
+
+// userListSpec 用户列表允许排序和过滤的字段
+var userListSpec = query.Spec{
+	Fields: map[string]query.Field{
+		"username":  {Column: "username", Sortable: true, Ops: []query.Op{query.OpEq, query.OpLike}},
+		"email":     {Column: "email", Sortable: true, Ops: []query.Op{query.OpEq, query.OpLike}},
+		"status":    {Column: "status", Ops: []query.Op{query.OpEq, query.OpNe, query.OpIn}},
+		"createdAt": {Column: "created_at", Kind: query.Time, Sortable: true, Ops: []query.Op{query.OpGt, query.OpGte, query.OpLt, query.OpLte}},
+	},
+	DefaultSort: "-createdAt",
+}
+
+// ListUsers 分页查询用户，支持按用户名模糊搜索和按状态过滤，以及通用的 sort、filter 和游标分页参数
+func ListUsers(c fiber.Ctx) error {
+	params, err := query.Parse(c.Queries(), userListSpec)
+	if err != nil {
+		return err
+	}
+
+	status := strings.TrimSpace(c.Query("status"))
+	users, info, err := db.ListUsers(strings.TrimSpace(c.Query("username")), status, params)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return response.Success(c, response.NewPage(users, info))
+}
+
+func GetUser(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+	return response.Success(c, user)
+}
+
+// requireRoleManage 分配角色前检查调用方是否拥有 role:manage 权限
+func requireRoleManage(c fiber.Ctx) error {
+	allowed, err := service.PrincipalFrom(c).HasPermission(model.PermissionRoleManage)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !allowed {
+		return errRoleManageRequired
+	}
+	return nil
+}
+
+// UpdateUser 修改用户名和角色，未传的字段保持不变；修改角色还需要 role:manage 权限，角色变化后吊销该用户的全部 token
+func UpdateUser(c fiber.Ctx) error {
+	var req UpdateUserRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+	if req.Roles != nil {
+		if err := requireRoleManage(c); err != nil {
+			return err
+		}
+	}
+
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+
+	if req.Username != nil {
+		username := strings.TrimSpace(*req.Username)
+		if username == "" {
+			return errUsernameRequired
+		}
+		if err := db.UpdateUserFields(&user, map[string]interface{}{"username": username}); err != nil {
+			return errUsernameTaken.Wrap(err)
+		}
+	}
+
+	if req.Roles != nil {
+		roles, err := db.GetRolesByNames(*req.Roles)
+		if err != nil {
+			if errors.Is(err, db.ErrRoleNotFound) {
+				return errRoleInvalid
+			}
+			return apperror.Internal(err)
+		}
+		changed := !sameRoles(user.Roles, roles)
+		if err := db.ReplaceUserRoles(&user, roles); err != nil {
+			return apperror.Internal(err)
+		}
+		// token 中的 roles 声明在过期前一直有效，降权后需要让已签发的 token 立即失效
+		if changed {
+			if err := service.RevokeAllUserTokens(user.Id); err != nil {
+				return apperror.Internal(err)
+			}
+		}
+	}
+
+	updated, err := db.GetUserById(user.Id.String())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminUserUpdate, &updated, updateDetail(req.Username, req.Roles))
+	return response.Success(c, updated)
+}
+
+// DisableUser 禁用用户并吊销其全部 token
+func DisableUser(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+	if isCurrentUser(c, &user) {
+		return errCannotDisableSelf
+	}
+
+	if err := db.UpdateUserFields(&user, map[string]interface{}{"status": model.StatusDisabled}); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminUserDisable, &user, "")
+
+	return response.Success(c, nil)
+}
+
+func EnableUser(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+
+	if err := db.UpdateUserFields(&user, map[string]interface{}{"status": model.StatusActive}); err != nil {
+		return apperror.Internal(err)
+	}
+	service.ForgetUserTokenState(user.Id)
+	auditUser(c, model.AuditAdminUserEnable, &user, "")
+
+	return response.Success(c, nil)
+}
+
+// ApproveUser 审核通过待审核的用户，通过后即可登录
+func ApproveUser(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+	if user.Status != model.StatusPending {
+		return errUserNotPending
+	}
+
+	if err := db.UpdateUserFields(&user, map[string]interface{}{"status": model.StatusActive}); err != nil {
+		return apperror.Internal(err)
+	}
+	service.ForgetUserTokenState(user.Id)
+	auditUser(c, model.AuditAdminUserApprove, &user, "")
+
+	return response.Success(c, nil)
+}
+
+// RejectUser 拒绝待审核的用户，账号会被直接删除，之后可以使用同一用户名重新注册
+func RejectUser(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+	if user.Status != model.StatusPending {
+		return errUserNotPending
+	}
+
+	if err := db.DeleteUser(&user); err != nil {
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminUserReject, &user, "")
+
+	return response.Success(c, nil)
+}
+
+// ResetUserPassword 管理员重置用户密码，用户已有的会话全部失效
+func ResetUserPassword(c fiber.Ctx) error {
+	var req ResetUserPasswordRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+	if err := password.Validate(req.Password, user.Username); err != nil {
+		var violations password.Violations
+		if errors.As(err, &violations) {
+			violations = violations.Localize(response.Locale(c))
+			return errPasswordPolicy.WithMessage(violations.Error()).WithDetails(violations)
+		}
+		return apperror.Internal(err)
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if err := db.UpdateUserFields(&user, map[string]interface{}{"password": hash}); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminPassword, &user, "")
+
+	return response.Success(c, nil)
+}
+
+func DeleteUser(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+	if isCurrentUser(c, &user) {
+		return errCannotDeleteSelf
+	}
+
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := db.DeleteUser(&user); err != nil {
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminUserDelete, &user, "")
+
+	return response.Success(c, nil)
+}
+
+// RevokeUserTokens 吊销指定用户的全部 token
+func RevokeUserTokens(c fiber.Ctx) error {
+	user, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminRevoke, &user, "")
+
+	return response.Success(c, nil)
+}
+
+// sameRoles 判断两组角色是否相同，忽略顺序
+func sameRoles(current []model.Role, next []model.Role) bool {
+	if len(current) != len(next) {
+		return false
+	}
+	names := make(map[string]bool, len(current))
+	for _, role := range current {
+		names[role.Name] = true
+	}
+	for _, role := range next {
+		if !names[role.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+func isCurrentUser(c fiber.Ctx, user *model.User) bool {
+	current, err := currentUser(c)
+	return err == nil && current.Id == user.Id
+}
+
+// auditUser 记录管理员对用户执行的操作
+func auditUser(c fiber.Ctx, action string, target *model.User, detail string) {
+	service.Audit(c, service.AuditEvent{Action: action, Detail: detail}.UserTarget(target))
+}
+
+func updateDetail(username *string, roles *[]string) string {
+	changes := make([]string, 0, 2)
+	if username != nil {
+		changes = append(changes, "username="+strings.TrimSpace(*username))
+	}
+	if roles != nil {
+		changes = append(changes, "roles="+strings.Join(*roles, ","))
+	}
+	return strings.Join(changes, "; ")
+}
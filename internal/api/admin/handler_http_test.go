@@ -0,0 +1,861 @@
+package admin
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+
+	"go-fiber-starter/internal/middleware"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/validate"
+)
+
+type responseEnvelope struct {
+	Flag  bool            `json:"flag"`
+	Code  int             `json:"code"`
+	Error string          `json:"error"`
+	Data  json.RawMessage `json:"data"`
+	Msg   string          `json:"msg"`
+}
+
+func setupTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	prevConfig := config.Current
+	config.Current.Jwt.Secret = "test-secret"
+	config.Current.Jwt.Expiration = 3600
+	config.Current.App.Env = "test"
+
+	prevDB := db.DB
+	gormDB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(gormDB); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	if err := db.Seed(gormDB); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	db.DB = gormDB
+
+	t.Cleanup(func() {
+		service.WaitAudit()
+		_ = sqlDB.Close()
+		config.Current = prevConfig
+		db.DB = prevDB
+	})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler, StructValidator: validate.Default})
+	api := app.Group("/api")
+	api.Use(middleware.Auth())
+	RegisterRoutes(api)
+
+	return app
+}
+
+// createUser 直接写库创建用户并签发 token
+func createUser(t *testing.T, username string, roles ...string) (*model.User, service.TokenPair) {
+	t.Helper()
+
+	user := &model.User{Username: username, Password: "x"}
+	if err := db.DB.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	roleModels, err := db.GetRolesByNames(roles)
+	if err != nil {
+		t.Fatalf("get roles: %v", err)
+	}
+	if err := db.AppendUserRoles(user, roleModels); err != nil {
+		t.Fatalf("append roles: %v", err)
+	}
+
+	loaded, err := db.GetUserById(user.Id.String())
+	if err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	pair, err := service.IssueTokenPair(&loaded, service.ClientInfo{})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	return &loaded, pair
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path string, token string, body ...interface{}) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if len(body) > 0 {
+		payload, err := json.Marshal(body[0])
+		if err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	return resp
+}
+
+func decodeEnvelope(t *testing.T, resp *http.Response) responseEnvelope {
+	t.Helper()
+	defer resp.Body.Close()
+	var envelope responseEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return envelope
+}
+
+func TestRevokeUserTokensRequiresPermission(t *testing.T) {
+	app := setupTestApp(t)
+	target, targetTokens := createUser(t, "target")
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	envelope := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/revoke-tokens", userTokens.Token))
+	if envelope.Flag || envelope.Code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got flag=%v code=%d", envelope.Flag, envelope.Code)
+	}
+
+	if _, err := service.RefreshTokenPair(targetTokens.RefreshToken, service.ClientInfo{}); err != nil {
+		t.Fatalf("target tokens should stay valid: %v", err)
+	}
+}
+
+func TestRevokeUserTokensAsAdmin(t *testing.T) {
+	app := setupTestApp(t)
+	target, targetTokens := createUser(t, "target")
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+
+	envelope := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/revoke-tokens", adminTokens.Token))
+	if !envelope.Flag {
+		t.Fatalf("revoke failed: %s", envelope.Msg)
+	}
+
+	if _, err := service.RefreshTokenPair(targetTokens.RefreshToken, service.ClientInfo{}); err == nil {
+		t.Fatalf("expected target refresh token to be revoked")
+	}
+}
+
+func TestListUsersSearchAndPaging(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	createUser(t, "alice")
+	createUser(t, "alicia")
+	createUser(t, "bob")
+
+	envelope := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users?username=ali&pageSize=1", adminTokens.Token))
+	if !envelope.Flag {
+		t.Fatalf("list failed: %s", envelope.Msg)
+	}
+	var list struct {
+		Items    []model.User `json:"items"`
+		Total    int64        `json:"total"`
+		PageSize int          `json:"pageSize"`
+	}
+	if err := json.Unmarshal(envelope.Data, &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if list.Total != 2 || len(list.Items) != 1 || list.PageSize != 1 {
+		t.Fatalf("unexpected list result: total=%d items=%d pageSize=%d", list.Total, len(list.Items), list.PageSize)
+	}
+
+	// 用户名中的通配符按字面匹配
+	createUser(t, "bo_b")
+	for keyword, want := range map[string]int64{"_": 1, "%25": 0} {
+		envelope = decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users?username="+keyword, adminTokens.Token))
+		if err := json.Unmarshal(envelope.Data, &list); err != nil {
+			t.Fatalf("decode list: %v", err)
+		}
+		if list.Total != want {
+			t.Fatalf("search %q: expected %d users, got %d", keyword, want, list.Total)
+		}
+	}
+}
+
+func TestListUsersSortFilterAndCursor(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	createUser(t, "carol")
+	createUser(t, "alice")
+	createUser(t, "bob")
+
+	var usernames []string
+	const listPath = "/api/admin/users?sort=username&filter[status][in]=active,pending&limit=2"
+	path := listPath
+	for range 4 {
+		envelope := decodeEnvelope(t, doRequest(t, app, http.MethodGet, path, adminTokens.Token))
+		if !envelope.Flag {
+			t.Fatalf("list failed: %s", envelope.Msg)
+		}
+		var page struct {
+			Items      []model.User `json:"items"`
+			Total      int64        `json:"total"`
+			NextCursor string       `json:"nextCursor"`
+			HasMore    bool         `json:"hasMore"`
+		}
+		if err := json.Unmarshal(envelope.Data, &page); err != nil {
+			t.Fatalf("decode page: %v", err)
+		}
+		if page.Total != 4 {
+			t.Fatalf("expected 4 users, got %d", page.Total)
+		}
+		for _, user := range page.Items {
+			usernames = append(usernames, user.Username)
+		}
+		if !page.HasMore {
+			break
+		}
+		path = listPath + "&after=" + page.NextCursor
+	}
+	if got := strings.Join(usernames, ","); got != "alice,bob,carol,root" {
+		t.Fatalf("unexpected cursor pages: %s", got)
+	}
+
+	for _, query := range []string{"sort=passwordHash", "filter[password][eq]=x", "filter[username][gt]=a"} {
+		envelope := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users?"+query, adminTokens.Token))
+		if envelope.Flag || envelope.Code != http.StatusBadRequest {
+			t.Fatalf("expected %s to be rejected, got code=%d msg=%s", query, envelope.Code, envelope.Msg)
+		}
+	}
+}
+
+func TestDisableUserRevokesAccess(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	target, targetTokens := createUser(t, "target", model.RoleAdmin)
+
+	envelope := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/disable", adminTokens.Token))
+	if !envelope.Flag {
+		t.Fatalf("disable failed: %s", envelope.Msg)
+	}
+
+	resp := doRequest(t, app, http.MethodGet, "/api/admin/users", targetTokens.Token)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected disabled user to be rejected, got %d", resp.StatusCode)
+	}
+	if _, err := service.RefreshTokenPair(targetTokens.RefreshToken, service.ClientInfo{}); err == nil {
+		t.Fatalf("expected disabled user refresh to fail")
+	}
+
+	// 重新签发的 token 也无法通过 CurrentUser
+	disabled, err := db.GetUserById(target.Id.String())
+	if err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if disabled.Status != model.StatusDisabled {
+		t.Fatalf("status %s != disabled", disabled.Status)
+	}
+	fresh, err := service.IssueTokenPair(&disabled, service.ClientInfo{})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/enable", fresh.Token))
+	if forbidden.Flag {
+		t.Fatalf("expected disabled user to be rejected")
+	}
+
+	enabled := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/enable", adminTokens.Token))
+	if !enabled.Flag {
+		t.Fatalf("enable failed: %s", enabled.Msg)
+	}
+}
+
+func TestUpdateAndDeleteUser(t *testing.T) {
+	app := setupTestApp(t)
+	admin, adminTokens := createUser(t, "root", model.RoleAdmin)
+	target, _ := createUser(t, "target", model.RoleUser)
+
+	updated := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/users/"+target.Id.String(), adminTokens.Token, fiber.Map{
+		"username": "renamed",
+		"roles":    []string{model.RoleAdmin},
+	}))
+	if !updated.Flag {
+		t.Fatalf("update failed: %s", updated.Msg)
+	}
+	var user model.User
+	if err := json.Unmarshal(updated.Data, &user); err != nil {
+		t.Fatalf("decode user: %v", err)
+	}
+	if user.Username != "renamed" || len(user.Roles) != 1 || user.Roles[0].Name != model.RoleAdmin {
+		t.Fatalf("unexpected updated user: %+v", user)
+	}
+
+	// 重复的角色名只计一次
+	duplicated := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/users/"+target.Id.String(), adminTokens.Token, fiber.Map{
+		"roles": []string{model.RoleUser, model.RoleUser},
+	}))
+	if !duplicated.Flag {
+		t.Fatalf("expected duplicate roles to be accepted: %s", duplicated.Msg)
+	}
+	if err := json.Unmarshal(duplicated.Data, &user); err != nil {
+		t.Fatalf("decode user: %v", err)
+	}
+	if len(user.Roles) != 1 || user.Roles[0].Name != model.RoleUser {
+		t.Fatalf("unexpected roles after duplicate update: %+v", user.Roles)
+	}
+
+	self := decodeEnvelope(t, doRequest(t, app, http.MethodDelete, "/api/admin/users/"+admin.Id.String(), adminTokens.Token))
+	if self.Flag {
+		t.Fatalf("expected self delete to be rejected")
+	}
+
+	if err := db.DB.Create(&model.UserToken{UserId: target.Id, Purpose: model.TokenPurposePasswordReset, TokenHash: "reset-hash", ExpiresAt: time.Now().Add(time.Hour)}).Error; err != nil {
+		t.Fatalf("create user token: %v", err)
+	}
+	if err := db.DB.Create(&model.LoginAttempt{Key: db.LoginAttemptAccountKey("renamed"), Failures: 2, LastFailureAt: time.Now()}).Error; err != nil {
+		t.Fatalf("create login attempt: %v", err)
+	}
+
+	deleted := decodeEnvelope(t, doRequest(t, app, http.MethodDelete, "/api/admin/users/"+target.Id.String(), adminTokens.Token))
+	if !deleted.Flag {
+		t.Fatalf("delete failed: %s", deleted.Msg)
+	}
+	var leftovers int64
+	db.DB.Model(&model.UserToken{}).Where("user_id = ?", target.Id).Count(&leftovers)
+	if leftovers != 0 {
+		t.Fatalf("expected user tokens to be deleted, %d left", leftovers)
+	}
+	db.DB.Model(&model.LoginAttempt{}).Where("attempt_key = ?", db.LoginAttemptAccountKey("renamed")).Count(&leftovers)
+	if leftovers != 0 {
+		t.Fatalf("expected login attempts to be deleted, %d left", leftovers)
+	}
+	missing := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users/"+target.Id.String(), adminTokens.Token))
+	if missing.Flag || missing.Code != http.StatusNotFound {
+		t.Fatalf("expected not found, got flag=%v code=%d", missing.Flag, missing.Code)
+	}
+}
+
+func TestUpdateUserRolesRequiresRoleManage(t *testing.T) {
+	app := setupTestApp(t)
+	var permissions []model.Permission
+	if err := db.DB.Where("code IN ?", []string{model.PermissionUserRead, model.PermissionUserWrite}).Find(&permissions).Error; err != nil {
+		t.Fatalf("load permissions: %v", err)
+	}
+	if err := db.DB.Create(&model.Role{Name: "support", Permissions: permissions}).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	helper, helperTokens := createUser(t, "helper", "support")
+
+	escalate := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/users/"+helper.Id.String(), helperTokens.Token, fiber.Map{
+		"roles": []string{model.RoleAdmin},
+	}))
+	if escalate.Flag || escalate.Code != http.StatusForbidden {
+		t.Fatalf("expected role change without role:manage to be forbidden, got flag=%v code=%d", escalate.Flag, escalate.Code)
+	}
+	reloaded, err := db.GetUserById(helper.Id.String())
+	if err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if len(reloaded.Roles) != 1 || reloaded.Roles[0].Name != "support" {
+		t.Fatalf("roles should be unchanged, got %+v", reloaded.Roles)
+	}
+
+	renamed := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/users/"+helper.Id.String(), helperTokens.Token, fiber.Map{
+		"username": "helper2",
+	}))
+	if !renamed.Flag {
+		t.Fatalf("rename with user:write should succeed: %s", renamed.Msg)
+	}
+}
+
+func TestCreateInviteWithRoleRequiresRoleManage(t *testing.T) {
+	app := setupTestApp(t)
+	var permissions []model.Permission
+	if err := db.DB.Where("code = ?", model.PermissionInviteManage).Find(&permissions).Error; err != nil {
+		t.Fatalf("load permissions: %v", err)
+	}
+	if err := db.DB.Create(&model.Role{Name: "recruiter", Permissions: permissions}).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	_, recruiterTokens := createUser(t, "recruiter", "recruiter")
+
+	escalate := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/invites", recruiterTokens.Token, fiber.Map{"role": model.RoleAdmin}))
+	if escalate.Flag || escalate.Code != http.StatusForbidden || escalate.Error != "role_manage_required" {
+		t.Fatalf("expected admin invite without role:manage to be forbidden, got flag=%v code=%d error=%s", escalate.Flag, escalate.Code, escalate.Error)
+	}
+	var count int64
+	db.DB.Model(&model.Invite{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected no invite to be created, got %d", count)
+	}
+
+	plain := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/invites", recruiterTokens.Token, fiber.Map{"note": "no role"}))
+	if !plain.Flag {
+		t.Fatalf("invite without role should succeed: %s", plain.Msg)
+	}
+}
+
+func TestDemotedUserLosesAdminAccess(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	deputy, deputyTokens := createUser(t, "deputy", model.RoleAdmin)
+
+	if resp := doRequest(t, app, http.MethodGet, "/api/admin/users", deputyTokens.Token); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected admin access before demotion, got %d", resp.StatusCode)
+	}
+
+	demoted := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/users/"+deputy.Id.String(), adminTokens.Token, fiber.Map{
+		"roles": []string{model.RoleUser},
+	}))
+	if !demoted.Flag {
+		t.Fatalf("demote failed: %s", demoted.Msg)
+	}
+
+	resp := doRequest(t, app, http.MethodGet, "/api/admin/users", deputyTokens.Token)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected demoted user's token to be rejected, got %d", resp.StatusCode)
+	}
+	if _, err := service.RefreshTokenPair(deputyTokens.RefreshToken, service.ClientInfo{}); err == nil {
+		t.Fatalf("expected demoted user refresh to fail")
+	}
+}
+
+func TestUpdateRoleRequireMfa(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/roles/"+model.RoleUser, userTokens.Token, map[string]bool{"requireMfa": true}))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+
+	updated := decodeEnvelope(t, doRequest(t, app, http.MethodPatch, "/api/admin/roles/"+model.RoleUser, adminTokens.Token, map[string]bool{"requireMfa": true}))
+	if !updated.Flag {
+		t.Fatalf("update role failed: %s", updated.Msg)
+	}
+	var role model.Role
+	if err := json.Unmarshal(updated.Data, &role); err != nil {
+		t.Fatalf("decode role: %v", err)
+	}
+	if !role.RequireMfa {
+		t.Fatalf("expected role to require mfa")
+	}
+
+	// 新签发的 token 带上两步验证绑定限制
+	_, restricted := createUser(t, "needs-mfa", model.RoleUser)
+	if !restricted.MfaSetupRequired {
+		t.Fatalf("expected token pair to require mfa setup")
+	}
+}
+
+func TestApiKeyScopesLimitAdminAccess(t *testing.T) {
+	app := setupTestApp(t)
+	admin, _ := createUser(t, "root", model.RoleAdmin)
+	target, _ := createUser(t, "target")
+
+	created, err := service.CreateApiKey(admin, "reporting", []string{model.PermissionUserRead}, nil)
+	if err != nil {
+		t.Fatalf("create api key: %v", err)
+	}
+
+	listed := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users", created.Token))
+	if !listed.Flag {
+		t.Fatalf("expected user:read scope to list users: %s", listed.Msg)
+	}
+
+	// 管理员本身拥有 user:write，但 API key 未被授予该 scope
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/disable", created.Token))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected missing scope to be forbidden, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+
+	// 所属用户失去角色后 API key 随之失去权限
+	if err := db.ReplaceUserRoles(admin, nil); err != nil {
+		t.Fatalf("replace roles: %v", err)
+	}
+	demoted := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users", created.Token))
+	if demoted.Flag || demoted.Code != http.StatusForbidden {
+		t.Fatalf("expected demoted owner key to be forbidden, got flag=%v code=%d", demoted.Flag, demoted.Code)
+	}
+}
+
+func TestInviteLifecycle(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/invites", userTokens.Token, fiber.Map{}))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected forbidden, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+
+	badRole := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/invites", adminTokens.Token, fiber.Map{"role": "missing"}))
+	if badRole.Flag || badRole.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown role to be rejected, got flag=%v code=%d", badRole.Flag, badRole.Code)
+	}
+
+	created := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/invites", adminTokens.Token, fiber.Map{
+		"role":      model.RoleUser,
+		"note":      "for carol",
+		"expiresAt": time.Now().Add(time.Hour),
+	}))
+	if !created.Flag {
+		t.Fatalf("create invite failed: %s", created.Msg)
+	}
+	var invite struct {
+		Code   string       `json:"code"`
+		Invite model.Invite `json:"invite"`
+	}
+	if err := json.Unmarshal(created.Data, &invite); err != nil {
+		t.Fatalf("decode invite: %v", err)
+	}
+	if invite.Code == "" || invite.Invite.Note != "for carol" {
+		t.Fatalf("unexpected invite: %+v", invite)
+	}
+
+	listed := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/invites", adminTokens.Token))
+	var invites []model.Invite
+	if err := json.Unmarshal(listed.Data, &invites); err != nil {
+		t.Fatalf("decode invites: %v", err)
+	}
+	if len(invites) != 1 || invites[0].Id != invite.Invite.Id {
+		t.Fatalf("unexpected invites: %+v", invites)
+	}
+
+	deleted := decodeEnvelope(t, doRequest(t, app, http.MethodDelete, "/api/admin/invites/"+invite.Invite.Id.String(), adminTokens.Token))
+	if !deleted.Flag {
+		t.Fatalf("delete invite failed: %s", deleted.Msg)
+	}
+	if _, err := service.FindInvite(invite.Code); err == nil {
+		t.Fatalf("expected deleted invite to be unusable")
+	}
+	missing := decodeEnvelope(t, doRequest(t, app, http.MethodDelete, "/api/admin/invites/"+invite.Invite.Id.String(), adminTokens.Token))
+	if missing.Flag || missing.Code != http.StatusNotFound {
+		t.Fatalf("expected not found, got flag=%v code=%d", missing.Flag, missing.Code)
+	}
+}
+
+func TestApproveAndRejectPendingUsers(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	active, _ := createUser(t, "active")
+	pending := []*model.User{{Username: "dave", Status: model.StatusPending}, {Username: "erin", Status: model.StatusPending}}
+	for _, user := range pending {
+		if err := db.DB.Create(user).Error; err != nil {
+			t.Fatalf("create pending user: %v", err)
+		}
+	}
+
+	listed := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users?status=pending", adminTokens.Token))
+	var list struct {
+		Total int64 `json:"total"`
+	}
+	if err := json.Unmarshal(listed.Data, &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if list.Total != 2 {
+		t.Fatalf("expected 2 pending users, got %d", list.Total)
+	}
+
+	notPending := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+active.Id.String()+"/approve", adminTokens.Token))
+	if notPending.Flag || notPending.Code != http.StatusBadRequest {
+		t.Fatalf("expected active user approval to be rejected, got flag=%v code=%d", notPending.Flag, notPending.Code)
+	}
+
+	approved := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+pending[0].Id.String()+"/approve", adminTokens.Token))
+	if !approved.Flag {
+		t.Fatalf("approve failed: %s", approved.Msg)
+	}
+	user, err := db.GetUserById(pending[0].Id.String())
+	if err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+	if user.Status != model.StatusActive {
+		t.Fatalf("status %s != active", user.Status)
+	}
+
+	rejected := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+pending[1].Id.String()+"/reject", adminTokens.Token))
+	if !rejected.Flag {
+		t.Fatalf("reject failed: %s", rejected.Msg)
+	}
+	if _, err := db.GetUserById(pending[1].Id.String()); err == nil {
+		t.Fatalf("expected rejected user to be deleted")
+	}
+}
+
+func TestAuditLogQueryAndExport(t *testing.T) {
+	app := setupTestApp(t)
+	admin, adminTokens := createUser(t, "root", model.RoleAdmin)
+	target, _ := createUser(t, "=target", model.RoleUser)
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	disabled := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/disable", adminTokens.Token))
+	if !disabled.Flag {
+		t.Fatalf("disable failed: %s", disabled.Msg)
+	}
+	resp := doRequest(t, app, http.MethodGet, "/api/admin/users", "not-a-jwt")
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected invalid token to be rejected, got %d", resp.StatusCode)
+	}
+	service.WaitAudit()
+
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/audit-logs", userTokens.Token))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected audit log access without permission to be rejected, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+
+	listed := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/audit-logs?action=admin.&targetId="+target.Id.String(), adminTokens.Token))
+	if !listed.Flag {
+		t.Fatalf("list audit logs failed: %s", listed.Msg)
+	}
+	var list struct {
+		Items []model.AuditLog `json:"items"`
+		Total int64            `json:"total"`
+	}
+	if err := json.Unmarshal(listed.Data, &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if list.Total != 1 || len(list.Items) != 1 {
+		t.Fatalf("expected 1 admin audit log, got %d", list.Total)
+	}
+	entry := list.Items[0]
+	if entry.Action != model.AuditAdminUserDisable || entry.Outcome != model.AuditSuccess ||
+		entry.ActorId != admin.Id.String() || entry.ActorName != "root" || entry.TargetName != "=target" {
+		t.Fatalf("unexpected audit log: %+v", entry)
+	}
+
+	failures := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/audit-logs?outcome=failure&action="+model.AuditTokenInvalid, adminTokens.Token))
+	if err := json.Unmarshal(failures.Data, &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if list.Total != 1 {
+		t.Fatalf("expected invalid token to be audited, got %d", list.Total)
+	}
+
+	badTime := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/audit-logs?from=yesterday", adminTokens.Token))
+	if badTime.Flag || badTime.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid time to be rejected, got flag=%v code=%d", badTime.Flag, badTime.Code)
+	}
+
+	exported := doRequest(t, app, http.MethodGet, "/api/admin/audit-logs/export?action="+model.AuditAdminUserDisable, adminTokens.Token)
+	defer exported.Body.Close()
+	if contentType := exported.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/csv") {
+		t.Fatalf("unexpected content type: %s", contentType)
+	}
+	records, err := csv.NewReader(exported.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 2 || records[1][1] != model.AuditAdminUserDisable {
+		t.Fatalf("unexpected csv: %v", records)
+	}
+	// 以 = 开头的用户名不能被表格软件当作公式
+	if records[1][9] != "'=target" {
+		t.Fatalf("expected formula to be escaped, got %q", records[1][9])
+	}
+
+	// 导出与列表使用相同的 filter 和 sort 参数
+	filtered := doRequest(t, app, http.MethodGet, "/api/admin/audit-logs/export?filter[targetId]="+target.Id.String()+"&sort=occurredAt", adminTokens.Token)
+	defer filtered.Body.Close()
+	records, err = csv.NewReader(filtered.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 2 || records[1][8] != target.Id.String() {
+		t.Fatalf("expected export to apply filter, got %v", records)
+	}
+	badSort := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/audit-logs/export?sort=detail", adminTokens.Token))
+	if badSort.Flag || badSort.Code != http.StatusBadRequest {
+		t.Fatalf("expected unsupported sort to be rejected, got flag=%v code=%d", badSort.Flag, badSort.Code)
+	}
+}
+
+func TestImpersonateUser(t *testing.T) {
+	app := setupTestApp(t)
+	admin, adminTokens := createUser(t, "root", model.RoleAdmin)
+	otherAdmin, _ := createUser(t, "root2", model.RoleAdmin)
+	target, _ := createUser(t, "customer", model.RoleUser)
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/impersonate", userTokens.Token))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected impersonation without permission to be rejected, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+	protected := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+otherAdmin.Id.String()+"/impersonate", adminTokens.Token))
+	if protected.Flag || protected.Code != http.StatusForbidden {
+		t.Fatalf("expected admin impersonation to be rejected, got flag=%v code=%d", protected.Flag, protected.Code)
+	}
+
+	tooLong := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/impersonate", adminTokens.Token, map[string]string{"reason": strings.Repeat("x", 256)}))
+	if tooLong.Flag || tooLong.Error != "validation_failed" {
+		t.Fatalf("expected overlong reason to be rejected, got flag=%v error=%s", tooLong.Flag, tooLong.Error)
+	}
+
+	issued := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/impersonate", adminTokens.Token, map[string]string{"reason": "ticket-42"}))
+	if !issued.Flag {
+		t.Fatalf("impersonate failed: %s", issued.Msg)
+	}
+	var token service.ImpersonationToken
+	if err := json.Unmarshal(issued.Data, &token); err != nil {
+		t.Fatalf("decode token: %v", err)
+	}
+	if token.Token == "" || token.ExpiresIn != int64(config.Current.Security.Impersonation.TTL().Seconds()) {
+		t.Fatalf("unexpected impersonation token: %+v", token)
+	}
+
+	// 模拟登录 token 只拥有目标用户的权限
+	denied := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/users", token.Token))
+	if denied.Flag || denied.Code != http.StatusForbidden {
+		t.Fatalf("expected impersonated user to lack admin permission, got flag=%v code=%d", denied.Flag, denied.Code)
+	}
+	service.WaitAudit()
+
+	var logs []model.AuditLog
+	if err := db.DB.Where("impersonator_id = ?", admin.Id.String()).Find(&logs).Error; err != nil {
+		t.Fatalf("query audit logs: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Action != model.AuditImpersonatedRequest || logs[0].ActorId != target.Id.String() ||
+		logs[0].ImpersonatorName != "root" || logs[0].Detail != "GET /api/admin/users" {
+		t.Fatalf("unexpected impersonated request logs: %+v", logs)
+	}
+	var issuedLog model.AuditLog
+	if err := db.DB.Where("action = ?", model.AuditAdminImpersonate).First(&issuedLog).Error; err != nil {
+		t.Fatalf("query impersonate audit log: %v", err)
+	}
+	if issuedLog.ActorId != admin.Id.String() || issuedLog.TargetId != target.Id.String() || issuedLog.Detail != "ticket-42" {
+		t.Fatalf("unexpected impersonate audit log: %+v", issuedLog)
+	}
+
+	// 管理员的 token 被吊销后模拟登录 token 同样失效
+	if err := service.RevokeAllUserTokens(admin.Id); err != nil {
+		t.Fatalf("revoke admin tokens: %v", err)
+	}
+	resp := doRequest(t, app, http.MethodGet, "/api/admin/users", token.Token)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected impersonation token to be revoked with actor, got %d", resp.StatusCode)
+	}
+}
+
+func TestClientLifecycle(t *testing.T) {
+	app := setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	forbidden := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/clients", userTokens.Token, map[string]interface{}{"name": "billing"}))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected client creation without permission to be rejected, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+	invalid := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/clients", adminTokens.Token, map[string]interface{}{
+		"name":   "billing",
+		"scopes": []string{"unknown:scope"},
+	}))
+	if invalid.Flag || invalid.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown scope to be rejected, got flag=%v code=%d", invalid.Flag, invalid.Code)
+	}
+
+	created := decodeEnvelope(t, doRequest(t, app, http.MethodPost, "/api/admin/clients", adminTokens.Token, map[string]interface{}{
+		"name":   "billing",
+		"scopes": []string{model.PermissionUserRead},
+	}))
+	if !created.Flag {
+		t.Fatalf("create client failed: %s", created.Msg)
+	}
+	var result service.CreatedClient
+	if err := json.Unmarshal(created.Data, &result); err != nil {
+		t.Fatalf("decode client: %v", err)
+	}
+
+	listed := decodeEnvelope(t, doRequest(t, app, http.MethodGet, "/api/admin/clients", adminTokens.Token))
+	if strings.Contains(string(listed.Data), result.ClientSecret) || !strings.Contains(string(listed.Data), result.Client.ClientId) {
+		t.Fatalf("unexpected client list %s", string(listed.Data))
+	}
+
+	revoked := decodeEnvelope(t, doRequest(t, app, http.MethodDelete, "/api/admin/clients/"+result.Client.Id.String(), adminTokens.Token))
+	if !revoked.Flag {
+		t.Fatalf("revoke client failed: %s", revoked.Msg)
+	}
+	again := decodeEnvelope(t, doRequest(t, app, http.MethodDelete, "/api/admin/clients/"+result.Client.Id.String(), adminTokens.Token))
+	if again.Flag || again.Code != http.StatusNotFound {
+		t.Fatalf("expected revoked client to be gone, got flag=%v code=%d", again.Flag, again.Code)
+	}
+	if _, err := service.AuthenticateClient(result.Client.ClientId, result.ClientSecret); err == nil {
+		t.Fatal("expected revoked client to fail authentication")
+	}
+}
+
+func TestProblemResponseMode(t *testing.T) {
+	setupTestApp(t)
+	_, adminTokens := createUser(t, "root", model.RoleAdmin)
+	target, _ := createUser(t, "target", model.RoleUser)
+	_, userTokens := createUser(t, "plain", model.RoleUser)
+
+	// 路由组单独启用 problem 模式，其余路由保持 legacy
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler, StructValidator: validate.Default})
+	api := app.Group("/api", middleware.ResponseMode(config.ResponseProblem))
+	api.Use(middleware.Auth())
+	RegisterRoutes(api)
+
+	resp := doRequest(t, app, http.MethodGet, "/api/admin/users", userTokens.Token)
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("expected problem response, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var problem map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	resp.Body.Close()
+	if problem["type"] != "about:blank" || problem["title"] != "Forbidden" || problem["status"] != float64(http.StatusForbidden) ||
+		problem["detail"] != "权限不足" || problem["instance"] != "/api/admin/users" {
+		t.Fatalf("unexpected problem: %v", problem)
+	}
+
+	resp = doRequest(t, app, http.MethodGet, "/api/admin/users", "")
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("expected unauthorized problem, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// 带附加数据的错误以扩展成员返回
+	resp = doRequest(t, app, http.MethodPost, "/api/admin/users/"+target.Id.String()+"/password", adminTokens.Token, map[string]string{"password": "a"})
+	problem = nil
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || problem["errors"] == nil {
+		t.Fatalf("expected violations as extension member, got %d %v", resp.StatusCode, problem)
+	}
+
+	resp = doRequest(t, app, http.MethodGet, "/api/admin/users/"+target.Id.String(), adminTokens.Token)
+	if resp.StatusCode != http.StatusOK || !decodeEnvelope(t, resp).Flag {
+		t.Fatalf("expected success envelope, got %d", resp.StatusCode)
+	}
+
+	// 显式指定 legacy 时覆盖全局配置
+	config.Current.App.ResponseMode = config.ResponseProblem
+	legacy := fiber.New()
+	legacyApi := legacy.Group("/api", middleware.ResponseMode(config.ResponseLegacy))
+	legacyApi.Use(middleware.Auth())
+	RegisterRoutes(legacyApi)
+	resp = doRequest(t, legacy, http.MethodGet, "/api/admin/users", userTokens.Token)
+	if envelope := decodeEnvelope(t, resp); resp.StatusCode != http.StatusOK || envelope.Code != http.StatusForbidden {
+		t.Fatalf("expected legacy envelope, got %d %+v", resp.StatusCode, envelope)
+	}
+}
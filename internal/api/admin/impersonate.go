@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ImpersonateUser 为指定用户签发短期模拟登录 token，供客服以该用户身份排查问题；reason 会写入审计日志
+func ImpersonateUser(c fiber.Ctx) error {
+	var req ImpersonateRequest
+	if err := c.Bind().Body(&req); err != nil && len(c.Body()) > 0 {
+		return errBadRequest.Wrap(err)
+	}
+
+	actor, err := currentActor(c)
+	if err != nil {
+		return err
+	}
+	target, err := db.GetUserById(c.Params("id"))
+	if err != nil {
+		return userLookupError(err)
+	}
+
+	token, err := service.Impersonate(actor, &target)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImpersonateSelf):
+			return errImpersonateSelf
+		case errors.Is(err, service.ErrImpersonateInactive):
+			return errImpersonateInactive
+		case errors.Is(err, service.ErrImpersonateProtected):
+			return errImpersonateProtected
+		}
+		return apperror.Internal(err)
+	}
+	auditUser(c, model.AuditAdminImpersonate, &target, strings.TrimSpace(req.Reason))
+
+	return response.Success(c, token)
+}
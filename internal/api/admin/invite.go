@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ListInvites 查询邀请码，默认只返回未使用的，includeUsed=true 时包含已使用的
+func ListInvites(c fiber.Ctx) error {
+	invites, err := db.ListInvites(c.Query("includeUsed") == "true")
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, invites)
+}
+
+// CreateInvite 创建一次性邀请码，可指定注册后额外授予的角色和过期时间；指定角色还需要 role:manage 权限
+func CreateInvite(c fiber.Ctx) error {
+	var req CreateInviteRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+	if strings.TrimSpace(req.Role) != "" {
+		if err := requireRoleManage(c); err != nil {
+			return err
+		}
+	}
+
+	current, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	created, err := service.CreateInvite(current.Id, req.Role, req.Note, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, service.ErrInviteRole) {
+			return errRoleInvalid
+		}
+		if errors.Is(err, service.ErrInviteExpiresPast) {
+			return errExpiresPast
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{
+		Action:     model.AuditAdminInviteAdd,
+		TargetType: "invite",
+		TargetId:   created.Invite.Id.String(),
+		Detail:     "role=" + req.Role,
+	})
+	return response.Success(c, created)
+}
+
+// DeleteInvite 作废尚未使用的邀请码
+func DeleteInvite(c fiber.Ctx) error {
+	deleted, err := db.DeleteUnusedInvite(c.Params("id"))
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !deleted {
+		return errInviteNotFound
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditAdminInviteDel, TargetType: "invite", TargetId: c.Params("id")})
+	return response.Success(c, nil)
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"errors"
+	"strconv"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func ListRoles(c fiber.Ctx) error {
+	roles, err := db.ListRoles()
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, roles)
+}
+
+// UpdateRole 修改角色设置，目前支持配置是否要求两步验证
+func UpdateRole(c fiber.Ctx) error {
+	var req UpdateRoleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	role, err := db.GetRoleByName(c.Params("name"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errRoleNotFound.Wrap(err)
+		}
+		return apperror.Internal(err)
+	}
+
+	if req.RequireMfa != nil {
+		if err := db.UpdateRoleFields(&role, map[string]interface{}{"require_mfa": *req.RequireMfa}); err != nil {
+			return apperror.Internal(err)
+		}
+	}
+
+	updated, err := db.GetRoleByName(role.Name)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	detail := ""
+	if req.RequireMfa != nil {
+		detail = "requireMfa=" + strconv.FormatBool(*req.RequireMfa)
+	}
+	service.Audit(c, service.AuditEvent{
+		Action:     model.AuditAdminRoleUpdate,
+		TargetType: "role",
+		TargetId:   updated.Id.String(),
+		TargetName: updated.Name,
+		Detail:     detail,
+	})
+	return response.Success(c, updated)
+}
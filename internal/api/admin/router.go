@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"go-fiber-starter/internal/middleware"
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func RegisterRoutes(router fiber.Router) {
+	// 管理后台显式允许跨租户访问，各路由仍需单独校验权限
+	grp := router.Group("/admin", middleware.CrossTenant())
+
+	users := grp.Group("/users")
+	users.Get("", middleware.RequirePermission(model.PermissionUserRead), ListUsers)
+	users.Get("/:id", middleware.RequirePermission(model.PermissionUserRead), GetUser)
+	users.Patch("/:id", middleware.RequirePermission(model.PermissionUserWrite), UpdateUser)
+	users.Post("/:id/disable", middleware.RequirePermission(model.PermissionUserWrite), DisableUser)
+	users.Post("/:id/enable", middleware.RequirePermission(model.PermissionUserWrite), EnableUser)
+	users.Post("/:id/approve", middleware.RequirePermission(model.PermissionUserWrite), ApproveUser)
+	users.Post("/:id/reject", middleware.RequirePermission(model.PermissionUserWrite), RejectUser)
+	users.Post("/:id/password", middleware.RequirePermission(model.PermissionUserWrite), ResetUserPassword)
+	users.Delete("/:id", middleware.RequirePermission(model.PermissionUserWrite), DeleteUser)
+	users.Post("/:id/revoke-tokens", middleware.RequirePermission(model.PermissionTokenRevoke), RevokeUserTokens)
+	users.Post("/:id/impersonate", middleware.RequirePermission(model.PermissionUserImpersonate), ImpersonateUser)
+
+	roles := grp.Group("/roles", middleware.RequirePermission(model.PermissionRoleManage))
+	roles.Get("", ListRoles)
+	roles.Patch("/:name", UpdateRole)
+
+	invites := grp.Group("/invites", middleware.RequirePermission(model.PermissionInviteManage))
+	invites.Get("", ListInvites)
+	invites.Post("", CreateInvite)
+	invites.Delete("/:id", DeleteInvite)
+
+	clients := grp.Group("/clients", middleware.RequirePermission(model.PermissionClientManage))
+	clients.Get("", ListClients)
+	clients.Post("", CreateClient)
+	clients.Delete("/:id", RevokeClient)
+
+	audit := grp.Group("/audit-logs", middleware.RequirePermission(model.PermissionAuditRead))
+	audit.Get("", ListAuditLogs)
+	audit.Get("/export", ExportAuditLogs)
+}
@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/password"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ChangePassword 校验旧密码后修改密码，其他会话全部失效，当前会话返回新的 token；
+// 第三方登录创建的用户没有本地密码，首次设置时无需旧密码，设置后即可使用需要密码确认的操作
+func ChangePassword(c fiber.Ctx) error {
+	var req ChangePasswordRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if user.HasPassword() && !password.Verify(req.OldPassword, user.Password) {
+		service.Audit(c, service.AuditEvent{Action: model.AuditPasswordChange}.Failed("wrong_password"))
+		return errWrongOldPassword
+	}
+	if err := password.Validate(req.NewPassword, user.Username); err != nil {
+		return passwordRejected(c, err)
+	}
+
+	hash, err := hashPassword(req.NewPassword)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if err := db.UpdateUserFields(user, map[string]interface{}{"password": hash}); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditPasswordChange})
+
+	// 吊销后 token 版本已递增，重新读取用户再签发
+	refreshed, err := db.GetUserById(user.Id.String())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	pair, err := service.IssueTokenPair(&refreshed, service.ClientInfoFrom(c))
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return respondTokens(c, pair)
+}
+
+// UpdateProfile 修改个人资料，未传的字段保持不变
+func UpdateProfile(c fiber.Ctx) error {
+	var req UpdateProfileRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{})
+	if req.DisplayName != nil {
+		fields["display_name"] = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Email != nil {
+		email := strings.TrimSpace(*req.Email)
+		if email != "" {
+			taken, err := db.EmailTaken(email, user.Id.String())
+			if err != nil {
+				return apperror.Internal(err)
+			}
+			if taken {
+				return errEmailTaken
+			}
+		}
+		if !strings.EqualFold(email, user.Email) {
+			fields["email"] = email
+			fields["email_verified_at"] = nil
+		}
+	}
+	if req.AvatarUrl != nil {
+		fields["avatar_url"] = strings.TrimSpace(*req.AvatarUrl)
+	}
+	if req.Locale != nil {
+		fields["locale"] = strings.TrimSpace(*req.Locale)
+	}
+	if req.Timezone != nil {
+		fields["timezone"] = strings.TrimSpace(*req.Timezone)
+	}
+
+	if len(fields) > 0 {
+		if err := db.UpdateUserFields(user, fields); err != nil {
+			return apperror.Internal(err)
+		}
+	}
+
+	updated, err := db.GetUserById(user.Id.String())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if _, changed := fields["email"]; changed {
+		if err := service.SendEmailVerification(&updated); err != nil {
+			logger.Error("发送邮箱验证邮件失败: %v", err)
+		}
+	}
+	return response.Success(c, updated)
+}
+
+// DeleteAccount 校验密码后删除当前账号
+func DeleteAccount(c fiber.Ctx) error {
+	var req DeleteAccountRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if !user.HasPassword() {
+		return errPasswordNotSet
+	}
+	if !password.Verify(req.Password, user.Password) {
+		service.Audit(c, service.AuditEvent{Action: model.AuditAccountDelete}.Failed("wrong_password"))
+		return errWrongPassword
+	}
+
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	if err := db.DeleteUser(user); err != nil {
+		return apperror.Internal(err)
+	}
+	if service.CookieMode() {
+		service.ClearAuthCookies(c)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditAccountDelete})
+
+	return response.Success(c, nil)
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ListApiKeys 查询当前用户未吊销的 API key，不包含明文
+func ListApiKeys(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	keys, err := db.ListUserApiKeys(user.Id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, keys)
+}
+
+// CreateApiKey 创建 API key，明文只在本次响应中返回
+func CreateApiKey(c fiber.Ctx) error {
+	var req CreateApiKeyRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+	req.Name = strings.TrimSpace(req.Name)
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	created, err := service.CreateApiKey(user, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrApiKeyScope):
+			return errApiKeyScopeInvalid
+		case errors.Is(err, service.ErrApiKeyExpiresPast):
+			return errApiKeyExpiresPast
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{
+		Action:     model.AuditApiKeyCreate,
+		TargetType: "api_key",
+		TargetId:   created.ApiKey.Id.String(),
+		TargetName: created.ApiKey.Name,
+		Detail:     "scopes=" + strings.Join(created.ApiKey.Scopes, ","),
+	})
+	return response.Success(c, created)
+}
+
+// RevokeApiKey 吊销当前用户的 API key
+func RevokeApiKey(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.RevokeApiKey(user.Id, c.Params("id")); err != nil {
+		if errors.Is(err, service.ErrApiKeyInvalid) {
+			return errApiKeyNotFound
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditApiKeyRevoke, TargetType: "api_key", TargetId: c.Params("id")})
+	return response.Success(c, nil)
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"go-fiber-starter/internal/api/response"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// cookieTokens cookie 会话模式下将 token 写入 cookie，返回去掉 token 明文的结果，避免前端脚本接触 token；未启用时原样返回
+func cookieTokens(c fiber.Ctx, pair service.TokenPair) (service.TokenPair, error) {
+	if !service.CookieMode() {
+		return pair, nil
+	}
+	if err := service.SetAuthCookies(c, pair); err != nil {
+		return pair, err
+	}
+	pair.Token = ""
+	pair.RefreshToken = ""
+	return pair, nil
+}
+
+// respondTokens 返回新签发的 token 组合
+func respondTokens(c fiber.Ctx, pair service.TokenPair) error {
+	pair, err := cookieTokens(c, pair)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, pair)
+}
+
+// refreshTokenFrom 请求体未传 refresh token 时读取 cookie，通过 cookie 传递时要求 CSRF 校验通过
+func refreshTokenFrom(c fiber.Ctx, fromBody string) (token string, csrfValid bool) {
+	if fromBody != "" {
+		return fromBody, true
+	}
+	token = service.RefreshTokenFromCookie(c)
+	if token == "" {
+		return "", true
+	}
+	return token, service.ValidCsrf(c)
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"go-fiber-starter/pkg/validate"
+)
+
+// LoginRequest 用户名密码登录
+type LoginRequest struct {
+	Username string `json:"username" validate:"required" label:"field.username"`
+	Password string `json:"password" validate:"required" label:"field.password"`
+}
+
+// RegisterRequest 注册新用户，密码强度由密码策略单独校验；邀请注册模式下需要填写邀请码
+type RegisterRequest struct {
+	Username   string `json:"username" validate:"required,min=3,max=32,username" label:"field.username"`
+	Password   string `json:"password" validate:"required" label:"field.password"`
+	Email      string `json:"email" validate:"max=254,email" label:"field.email"`
+	InviteCode string `json:"inviteCode" label:"field.inviteCode"`
+}
+
+// RefreshRequest 刷新 token，退出登录时也可以传入要一并吊销的 refresh token；cookie 会话模式下可以不传
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// UpdateProfileRequest 修改个人资料，未传的字段保持不变，传空字符串表示清空
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName" validate:"max=64" label:"field.displayName"`
+	Email       *string `json:"email" validate:"max=254,email" label:"field.email"`
+	AvatarUrl   *string `json:"avatarUrl" validate:"max=512,http_url" label:"field.avatarUrl"`
+	Locale      *string `json:"locale" validate:"max=16,locale" label:"field.locale"`
+	Timezone    *string `json:"timezone" validate:"max=64,timezone" label:"field.timezone"`
+}
+
+// DeleteAccountRequest 注销账号，密码在处理时校验，以便没有本地密码的用户得到单独的提示
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// ChangePasswordRequest 修改密码，没有本地密码的用户首次设置时可以不填旧密码
+type ChangePasswordRequest struct {
+	OldPassword string `json:"oldPassword"`
+	NewPassword string `json:"newPassword" validate:"required" label:"field.newPassword"`
+}
+
+// ForgotPasswordRequest 申请重置密码邮件
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" label:"field.email"`
+}
+
+// ResetPasswordRequest 使用邮件中的 token 设置新密码
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required" label:"field.token"`
+	Password string `json:"password" validate:"required" label:"field.password"`
+}
+
+// VerifyEmailRequest 使用邮件中的 token 验证邮箱
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required" label:"field.token"`
+}
+
+// MfaCodeRequest 提交一次两步验证码，用于确认启用和重新生成恢复码
+type MfaCodeRequest struct {
+	Code string `json:"code" validate:"required" label:"field.mfaCode"`
+}
+
+// DisableMfaRequest 关闭两步验证，密码在处理时校验，以便没有本地密码的用户得到单独的提示
+type DisableMfaRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"required" label:"field.mfaCode"`
+}
+
+// VerifyMfaRequest 登录第二步，code 可以是验证码或恢复码
+type VerifyMfaRequest struct {
+	MfaToken string `json:"mfaToken" validate:"required" label:"field.mfaToken"`
+	Code     string `json:"code" validate:"required" label:"field.mfaCode"`
+}
+
+// CreateApiKeyRequest 创建 API key，scopes 只能是当前用户拥有的权限，expiresAt 为空时不过期
+type CreateApiKeyRequest struct {
+	Name      string     `json:"name" validate:"required,max=64" label:"field.name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+var (
+	usernamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.@-]+$`)
+	localePattern   = regexp.MustCompile(`^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
+)
+
+func init() {
+	validate.Register("username", func(value reflect.Value, _ string) bool {
+		return value.Kind() == reflect.String && usernamePattern.MatchString(value.String())
+	}, "validation.username")
+	validate.Register("http_url", func(value reflect.Value, _ string) bool {
+		if value.Kind() != reflect.String {
+			return false
+		}
+		parsed, err := url.Parse(strings.TrimSpace(value.String()))
+		return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+	}, "validation.http_url")
+	validate.Register("locale", func(value reflect.Value, _ string) bool {
+		return value.Kind() == reflect.String && localePattern.MatchString(strings.TrimSpace(value.String()))
+	}, "validation.locale")
+	validate.Register("timezone", func(value reflect.Value, _ string) bool {
+		if value.Kind() != reflect.String {
+			return false
+		}
+		_, err := time.LoadLocation(strings.TrimSpace(value.String()))
+		return err == nil
+	}, "validation.timezone")
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/password"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// VerifyEmail 使用邮件中的 token 完成邮箱验证
+func VerifyEmail(c fiber.Ctx) error {
+	var req VerifyEmailRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	if err := service.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, service.ErrUserTokenInvalid) {
+			return errEmailTokenInvalid
+		}
+		return apperror.Internal(err)
+	}
+	return response.Success(c, nil)
+}
+
+// ResendVerification 重新发送当前用户的邮箱验证邮件
+func ResendVerification(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return errEmailNotSet
+	}
+	if user.EmailVerifiedAt != nil {
+		return errEmailVerified
+	}
+
+	if err := service.SendEmailVerification(user); err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, nil)
+}
+
+// ForgotPassword 发送重置密码邮件，无论邮箱是否存在都返回成功
+func ForgotPassword(c fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	if err := service.RequestPasswordReset(strings.TrimSpace(req.Email)); err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, nil)
+}
+
+// ResetPassword 使用邮件中的 token 设置新密码
+func ResetPassword(c fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := service.PasswordResetUser(req.Token)
+	if err != nil {
+		if errors.Is(err, service.ErrUserTokenInvalid) {
+			service.Audit(c, service.AuditEvent{Action: model.AuditPasswordReset}.Failed("invalid_token"))
+		}
+		return resetPasswordError(err)
+	}
+	if err := password.Validate(req.Password, user.Username); err != nil {
+		return passwordRejected(c, err)
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if err := service.ResetPassword(req.Token, hash); err != nil {
+		return resetPasswordError(err)
+	}
+	service.AuditAs(c, &user, service.AuditEvent{Action: model.AuditPasswordReset})
+	return response.Success(c, nil)
+}
+
+func resetPasswordError(err error) error {
+	if errors.Is(err, service.ErrUserTokenInvalid) {
+		return errResetTokenInvalid
+	}
+	return apperror.Internal(err)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"errors"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// 认证接口返回的应用错误，错误码对外稳定，客户端可以据此区分失败原因；提示为语言包中的 key
+var (
+	errBadRequest         = apperror.Validation(apperror.CodeBadRequest, "error.bad_request")
+	errRegistrationClosed = apperror.Forbidden("registration_closed", "auth.registration_closed")
+	errEmailTaken         = apperror.Conflict("email_taken", "auth.email_taken")
+	errUsernameTaken      = apperror.Conflict("username_taken", "auth.username_taken")
+	errInviteInvalid      = apperror.Validation("invite_invalid", "auth.invite_invalid")
+	errPasswordPolicy     = apperror.Validation("password_policy", "auth.password_policy")
+	errInvalidCredentials = apperror.Unauthorized("invalid_credentials", "auth.invalid_credentials")
+	errTooManyAttempts    = apperror.RateLimited("too_many_attempts", "auth.too_many_attempts", 0)
+	errAccountPending     = apperror.Forbidden("account_pending", "auth.account_pending")
+	errAccountDisabled    = apperror.Forbidden("account_disabled", "auth.account_disabled")
+	// errPasswordNotSet 第三方登录创建的用户需先设置密码，才能使用需要密码确认的操作
+	errPasswordNotSet   = apperror.Validation("password_not_set", "auth.password_not_set")
+	errUserNotFound     = apperror.NotFound("user_not_found", "auth.user_not_found")
+	errRefreshInvalid   = apperror.Unauthorized("refresh_invalid", "auth.refresh_invalid")
+	errCsrfFailed       = apperror.Forbidden("csrf_failed", "auth.csrf_failed")
+	errNotImpersonating = apperror.Validation("not_impersonating", "auth.not_impersonating")
+)
+
+// 账号、会话与 API key
+var (
+	errWrongOldPassword    = apperror.Validation("old_password_incorrect", "auth.old_password_incorrect")
+	errWrongPassword       = apperror.Validation("password_incorrect", "auth.password_incorrect")
+	errSessionNotFound     = apperror.NotFound("session_not_found", "auth.session_not_found")
+	errApiKeyScopeInvalid  = apperror.Validation("scope_invalid", "error.scope_invalid")
+	errApiKeyExpiresPast   = apperror.Validation("expires_in_past", "error.expires_in_past")
+	errApiKeyNotFound      = apperror.NotFound("api_key_not_found", "auth.api_key_not_found")
+	errEmailTokenInvalid   = apperror.Validation("email_token_invalid", "auth.email_token_invalid")
+	errEmailNotSet         = apperror.Validation("email_not_set", "auth.email_not_set")
+	errEmailVerified       = apperror.Validation("email_already_verified", "auth.email_already_verified")
+	errResetTokenInvalid   = apperror.Validation("reset_token_invalid", "auth.reset_token_invalid")
+	errLastIdentity        = apperror.Validation("last_identity", "auth.last_identity")
+	errIdentityNotFound    = apperror.NotFound("identity_not_found", "auth.identity_not_found")
+	errOidcProviderUnknown = apperror.NotFound("oidc_provider_not_found", "auth.oidc_provider_not_found")
+	errOidcCancelled       = apperror.Validation("oidc_cancelled", "auth.oidc_cancelled")
+	errOidcStateInvalid    = apperror.Validation("oidc_state_invalid", "auth.oidc_state_invalid")
+	errOidcNotLinked       = apperror.Forbidden("oidc_not_linked", "auth.oidc_not_linked")
+	errOidcLoginFailed     = apperror.Unauthorized("oidc_login_failed", "auth.oidc_login_failed")
+)
+
+// 两步验证
+var (
+	errMfaAlreadyEnabled  = apperror.Validation("mfa_already_enabled", "auth.mfa_already_enabled")
+	errMfaNotEnabled      = apperror.Validation("mfa_not_enabled", "auth.mfa_not_enabled")
+	errMfaNotEnrolled     = apperror.Validation("mfa_not_enrolled", "auth.mfa_not_enrolled")
+	errMfaCodeInvalid     = apperror.Validation("mfa_code_invalid", "auth.mfa_code_invalid")
+	errMfaPendingInvalid  = apperror.Unauthorized("mfa_pending_invalid", "auth.mfa_pending_invalid")
+	errMfaTooManyAttempts = apperror.Unauthorized("mfa_too_many_attempts", "auth.mfa_too_many_attempts")
+	errMfaRequiredByRole  = apperror.Forbidden("mfa_required_by_role", "auth.mfa_required_by_role")
+)
+
+// currentUser 读取当前登录用户，用户已被删除、禁用或调用方不是用户时返回 404，其余错误按服务端错误处理
+func currentUser(c fiber.Ctx) (*model.User, error) {
+	user, err := service.CurrentUser(c)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrUserDisabled) || errors.Is(err, service.ErrNotUser) {
+			return nil, errUserNotFound.Wrap(err)
+		}
+		return nil, apperror.Internal(err)
+	}
+	return user, nil
+}
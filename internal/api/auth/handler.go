@@ -8,72 +8,347 @@
 package auth
 
 import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
 	"go-fiber-starter/internal/api/response"
 	model "go-fiber-starter/internal/model/user"
 	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/config"
 	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/jwtkey"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/password"
 
+	jwtware "github.com/gofiber/contrib/v3/jwt"
 	"github.com/gofiber/fiber/v3"
-	"golang.org/x/crypto/bcrypt"
 )
 
-var generateFromPassword = bcrypt.GenerateFromPassword
+var hashPassword = password.Hash
 
+// @Summary 用户注册
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param register body RegisterRequest true "注册信息"
+// @Success 200 {object} response.Response{data=model.User}
+// @Failure 400 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Router /api/auth/register [post]
 func Register(c fiber.Ctx) error {
-	var req struct{ Username, Password string }
+	var req RegisterRequest
 
 	if err := c.Bind().Body(&req); err != nil {
-		return response.Error(c, "参数不正确")
+		return errBadRequest.Wrap(err)
+	}
+
+	mode := config.Current.App.Registration()
+	if mode == config.RegistrationDisabled {
+		return errRegistrationClosed
+	}
+	var invite *model.Invite
+	if mode == config.RegistrationInvite {
+		found, err := service.FindInvite(req.InviteCode)
+		if err != nil {
+			return inviteError(err)
+		}
+		invite = &found
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email != "" {
+		taken, err := db.EmailTaken(req.Email, "")
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if taken {
+			return errEmailTaken
+		}
+	}
+
+	if err := password.Validate(req.Password, req.Username); err != nil {
+		return passwordRejected(c, err)
 	}
 
-	hash, err := generateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hash, err := hashPassword(req.Password)
 	if err != nil {
-		return response.Error(c, "密码加密失败")
+		return apperror.Internal(err)
 	}
-	user := model.User{Username: req.Username, Password: string(hash)}
-	if err := db.DB.Create(&user).Error; err != nil {
-		return response.Error(c, "用户名已存在")
+	user := model.User{Username: req.Username, Password: hash, Email: req.Email}
+	if mode == config.RegistrationApproval {
+		user.Status = model.StatusPending
 	}
+	if err := service.AssignDefaultRoles(&user, invite); err != nil {
+		return apperror.Internal(err)
+	}
+	if invite != nil {
+		err = service.CreateInvitedUser(&user, invite)
+	} else {
+		err = db.DB.Create(&user).Error
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrInviteInvalid) {
+			return inviteError(err)
+		}
+		service.Audit(c, service.AuditEvent{Action: model.AuditRegister, ActorName: req.Username}.Failed("username_taken"))
+		return errUsernameTaken.Wrap(err)
+	}
+	// 验证邮件异步发送，发送失败不影响注册结果
+	if err := service.SendEmailVerification(&user); err != nil {
+		logger.Error("发送邮箱验证邮件失败: %v", err)
+	}
+	service.AuditAs(c, &user, service.AuditEvent{Action: model.AuditRegister, Detail: "mode=" + mode})
 
 	return response.Success(c, user)
 }
 
+func inviteError(err error) error {
+	if errors.Is(err, service.ErrInviteInvalid) {
+		return errInviteInvalid.Wrap(err)
+	}
+	return apperror.Internal(err)
+}
+
+// inactiveUser 未启用的用户不能登录，待审核用户给出单独的提示
+func inactiveUser(c fiber.Ctx, user *model.User) error {
+	service.AuditAs(c, user, service.AuditEvent{Action: model.AuditLogin}.Failed(user.Status))
+	if user.Status == model.StatusPending {
+		return errAccountPending
+	}
+	return errAccountDisabled
+}
+
 // @Summary 用户登录
 // @Description 用户登录接口
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param login body LoginRequest true "登录信息"
-// @Success 200 {object} LoginResponse
-// @Failure 401 {object} ErrorResponse
+// @Success 200 {object} response.Response{data=service.TokenPair}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
 // @Router /api/auth/login [post]
 func Login(c fiber.Ctx) error {
-	var req struct{ Username, Password string }
+	var req LoginRequest
 
 	if err := c.Bind().Body(&req); err != nil {
-		return response.Error(c, "参数不正确")
+		return errBadRequest.Wrap(err)
+	}
+
+	ip := c.IP()
+	retryAfter, err := service.LoginRetryAfter(req.Username, ip)
+	if err != nil {
+		logger.Error("读取登录失败记录失败: %v", err)
+	}
+	if retryAfter > 0 {
+		service.Audit(c, service.AuditEvent{Action: model.AuditLogin, ActorName: req.Username}.Failed("locked"))
+		return tooManyLoginAttempts(retryAfter)
 	}
 
 	var user model.User
-	if err := db.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		return response.Error(c, "用户名不存在")
+	if err := db.DB.Preload("Roles").Where("username = ?", req.Username).First(&user).Error; err != nil {
+		// 用户不存在时同样做一次哈希比较，避免通过响应时间判断用户名是否存在
+		_ = password.Verify(req.Password, dummyPasswordHash())
+		return loginFailed(c, req.Username)
+	}
+
+	if !password.Verify(req.Password, user.Password) {
+		return loginFailed(c, req.Username)
+	}
+	// 哈希算法或参数已过时，趁持有明文密码时按当前配置重新生成
+	if password.NeedsRehash(user.Password) {
+		if hash, err := hashPassword(req.Password); err != nil {
+			logger.Error("重新生成密码哈希失败: %v", err)
+		} else if err := db.UpdateUserFields(&user, map[string]interface{}{"password": hash}); err != nil {
+			logger.Error("升级密码哈希失败: %v", err)
+		}
+	}
+
+	// 启用两步验证时密码正确不代表登录成功，失败次数在验证码通过后再清除
+	if !user.MfaEnabled {
+		if err := service.ResetLoginFailures(req.Username); err != nil {
+			logger.Error("清除登录失败记录失败: %v", err)
+		}
 	}
 
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)) != nil {
-		return response.Error(c, "密码不正确")
+	if !user.IsActive() {
+		return inactiveUser(c, &user)
 	}
 
-	token, err := service.GenerateJWT(&user)
+	return issueLoginResponse(c, &user, "password")
+}
+
+// passwordRejected 密码不符合策略时按请求的语言逐条返回未满足的规则
+func passwordRejected(c fiber.Ctx, err error) error {
+	var violations password.Violations
+	if errors.As(err, &violations) {
+		violations = violations.Localize(response.Locale(c))
+		return errPasswordPolicy.WithMessage(violations.Error()).WithDetails(violations)
+	}
+	return apperror.Internal(err)
+}
+
+// loginFailed 用户名不存在与密码错误返回相同的提示，并累计失败次数
+func loginFailed(c fiber.Ctx, username string) error {
+	lockout, err := service.RecordLoginFailure(username, service.ClientInfoFrom(c))
 	if err != nil {
-		return response.Error(c, "token生成失败")
+		logger.Error("记录登录失败失败: %v", err)
 	}
-	return response.Success(c, fiber.Map{"token": token})
+	if lockout > 0 {
+		// 锁定详情见同时写入的 auth.login_lockout 事件
+		service.Audit(c, service.AuditEvent{Action: model.AuditLogin, ActorName: username}.Failed("invalid_credentials, locked"))
+		return tooManyLoginAttempts(lockout)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditLogin, ActorName: username}.Failed("invalid_credentials"))
+	return errInvalidCredentials
+}
+
+func tooManyLoginAttempts(retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	err := errTooManyAttempts.WithParams(map[string]interface{}{"count": seconds})
+	err.RetryAfter = retryAfter
+	return err
+}
+
+// dummyPasswordHash 首次使用时生成，避免包初始化时就做一次 bcrypt 计算
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hash, _ := password.Hash("go-fiber-starter")
+	return hash
+})
+
+// issueLoginResponse 身份校验通过后签发 token，已启用两步验证的用户先返回 mfa pending token；method 为登录方式，写入审计日志
+func issueLoginResponse(c fiber.Ctx, user *model.User, method string) error {
+	if user.MfaEnabled {
+		challenge, err := service.IssueMfaChallenge(user)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		service.AuditAs(c, user, service.AuditEvent{Action: model.AuditLogin, Detail: method + ", mfa_required"})
+		return response.Success(c, challenge)
+	}
+
+	pair, err := service.IssueTokenPair(user, service.ClientInfoFrom(c))
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	service.AuditAs(c, user, service.AuditEvent{Action: model.AuditLogin, Detail: method})
+	return respondTokens(c, pair)
+}
+
+// Refresh 使用 refresh token 换取新的 token 组合，每次调用都会轮换 refresh token；
+// cookie 会话模式下请求体可以为空，从 cookie 中读取 refresh token
+func Refresh(c fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.Bind().Body(&req); err != nil && len(c.Body()) > 0 {
+		return errBadRequest.Wrap(err)
+	}
+
+	refreshToken, csrfValid := refreshTokenFrom(c, req.RefreshToken)
+	if refreshToken == "" {
+		return errBadRequest
+	}
+	if !csrfValid {
+		return errCsrfFailed
+	}
+
+	pair, err := service.RefreshTokenPair(refreshToken, service.ClientInfoFrom(c))
+	if err != nil {
+		if errors.Is(err, service.ErrRefreshTokenInvalid) ||
+			errors.Is(err, service.ErrRefreshTokenExpired) ||
+			errors.Is(err, service.ErrRefreshTokenReused) {
+			// 重放在 service 中已按所属用户记录
+			if !errors.Is(err, service.ErrRefreshTokenReused) {
+				service.Audit(c, service.AuditEvent{Action: model.AuditTokenRefresh}.Failed(err.Error()))
+			}
+			if service.CookieMode() {
+				service.ClearAuthCookies(c)
+			}
+			return errRefreshInvalid.Wrap(err)
+		}
+		return apperror.Internal(err)
+	}
+	return respondTokens(c, pair)
 }
 
 func Profile(c fiber.Ctx) error {
-	user, err := service.CurrentUser(c)
+	user, err := currentUser(c)
 	if err != nil {
-		return response.Error(c, "用户未找到")
+		return err
 	}
 	return response.Success(c, user)
 }
+
+// Logout 退出当前会话：吊销当前 access token 及其所属会话，并可同时吊销传入的 refresh token
+func Logout(c fiber.Ctx) error {
+	var req RefreshRequest
+	if err := c.Bind().Body(&req); err != nil && len(c.Body()) > 0 {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	token := jwtware.FromContext(c)
+	if err := service.RevokeToken(token); err != nil {
+		return apperror.Internal(err)
+	}
+	if sessionId := service.TokenSessionId(token); sessionId != "" {
+		if err := service.RevokeUserSession(user.Id, sessionId); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+			return apperror.Internal(err)
+		}
+	}
+	if refreshToken, _ := refreshTokenFrom(c, req.RefreshToken); refreshToken != "" {
+		if err := service.RevokeRefreshToken(user.Id, refreshToken); err != nil && !errors.Is(err, service.ErrRefreshTokenInvalid) {
+			return apperror.Internal(err)
+		}
+	}
+	if service.CookieMode() {
+		service.ClearAuthCookies(c)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditLogout, TargetType: "session", TargetId: service.TokenSessionId(token)})
+
+	return response.Success(c, nil)
+}
+
+// LogoutAll 退出当前用户的所有会话
+func LogoutAll(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.RevokeAllUserTokens(user.Id); err != nil {
+		return apperror.Internal(err)
+	}
+	if service.CookieMode() {
+		service.ClearAuthCookies(c)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditLogoutAll})
+
+	return response.Success(c, nil)
+}
+
+// StopImpersonation 结束模拟登录，当前模拟登录 token 立即失效
+func StopImpersonation(c fiber.Ctx) error {
+	if err := service.StopImpersonation(service.PrincipalFrom(c), jwtware.FromContext(c)); err != nil {
+		if errors.Is(err, service.ErrNotImpersonating) {
+			return errNotImpersonating
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditImpersonationStop})
+
+	return response.Success(c, nil)
+}
+
+// JWKS 公开当前可用于验签的公钥集合
+func JWKS(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(jwtkey.Active().JWKS())
+}
@@ -8,22 +8,24 @@ import (
 	"strings"
 	"testing"
 
+	"go-fiber-starter/internal/middleware"
+
 	"github.com/gofiber/fiber/v3"
 )
 
 func TestRegister_HashError(t *testing.T) {
-	original := generateFromPassword
+	original := hashPassword
 	t.Cleanup(func() {
-		generateFromPassword = original
+		hashPassword = original
 	})
-	generateFromPassword = func(_ []byte, _ int) ([]byte, error) {
-		return nil, errors.New("boom")
+	hashPassword = func(_ string) (string, error) {
+		return "", errors.New("boom")
 	}
 
-	app := fiber.New()
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler})
 	app.Post("/api/auth/register", Register)
 
-	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader("{\"username\":\"alice\",\"password\":\"secret\"}"))
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader("{\"username\":\"alice\",\"password\":\"secret123\"}"))
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := app.Test(req)
@@ -33,9 +35,10 @@ func TestRegister_HashError(t *testing.T) {
 	defer resp.Body.Close()
 
 	var payload struct {
-		Flag bool   `json:"flag"`
-		Code int    `json:"code"`
-		Msg  string `json:"msg"`
+		Flag  bool   `json:"flag"`
+		Code  int    `json:"code"`
+		Error string `json:"error"`
+		Msg   string `json:"msg"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
 		t.Fatalf("decode response: %v", err)
@@ -47,7 +50,8 @@ func TestRegister_HashError(t *testing.T) {
 	if payload.Code != http.StatusInternalServerError {
 		t.Fatalf("expected code %d, got %d", http.StatusInternalServerError, payload.Code)
 	}
-	if payload.Msg != "密码加密失败" {
-		t.Fatalf("expected message %q, got %q", "密码加密失败", payload.Msg)
+	// 内部错误只返回通用提示，原因写入日志
+	if payload.Error != "internal_error" || strings.Contains(payload.Msg, "boom") {
+		t.Fatalf("expected generic internal error, got error=%q msg=%q", payload.Error, payload.Msg)
 	}
 }
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"errors"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/password"
+
+	jwtware "github.com/gofiber/contrib/v3/jwt"
+	"github.com/gofiber/fiber/v3"
+)
+
+// MfaStatus 查询当前用户两步验证状态
+func MfaStatus(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	remaining, err := db.CountUnusedRecoveryCodes(user.Id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, fiber.Map{
+		"enabled":                user.MfaEnabled,
+		"required":               user.MfaEnabled || service.MfaEnrollmentRequired(user),
+		"recoveryCodesRemaining": remaining,
+	})
+}
+
+// EnrollMfa 生成 TOTP 密钥和扫码地址，需调用 ConfirmMfa 确认后才会启用
+func EnrollMfa(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := service.StartMfaEnrollment(user)
+	if err != nil {
+		if errors.Is(err, service.ErrMfaAlreadyEnabled) {
+			return errMfaAlreadyEnabled
+		}
+		return apperror.Internal(err)
+	}
+	return response.Success(c, enrollment)
+}
+
+// ConfirmMfa 校验验证码后启用两步验证，返回恢复码和不再受限的新 token
+func ConfirmMfa(c fiber.Ctx) error {
+	var req MfaCodeRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	codes, err := service.ConfirmMfaEnrollment(user, req.Code)
+	if err != nil {
+		return mfaError(err)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditMfaEnable})
+
+	// 启用后重新签发，去掉 token 上的两步验证绑定限制
+	if err := service.RevokeToken(jwtware.FromContext(c)); err != nil {
+		return apperror.Internal(err)
+	}
+	refreshed, err := db.GetUserById(user.Id.String())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	pair, err := service.IssueTokenPair(&refreshed, service.ClientInfoFrom(c))
+	if err == nil {
+		pair, err = cookieTokens(c, pair)
+	}
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, fiber.Map{"recoveryCodes": codes, "tokens": pair})
+}
+
+// DisableMfa 校验密码和验证码后关闭两步验证
+func DisableMfa(c fiber.Ctx) error {
+	var req DisableMfaRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if !user.MfaEnabled {
+		return errMfaNotEnabled
+	}
+	if !user.HasPassword() {
+		return errPasswordNotSet
+	}
+	if !password.Verify(req.Password, user.Password) {
+		return errWrongPassword
+	}
+	if err := service.VerifyMfaCode(user, req.Code, true); err != nil {
+		return mfaError(err)
+	}
+
+	if err := service.DisableMfa(user); err != nil {
+		return mfaError(err)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditMfaDisable})
+	return response.Success(c, nil)
+}
+
+// RegenerateRecoveryCodes 使用当前验证码重新生成恢复码，旧恢复码全部作废
+func RegenerateRecoveryCodes(c fiber.Ctx) error {
+	var req MfaCodeRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	if !user.MfaEnabled {
+		return errMfaNotEnabled
+	}
+	if err := service.VerifyMfaCode(user, req.Code, false); err != nil {
+		return mfaError(err)
+	}
+
+	codes, err := service.RegenerateRecoveryCodes(user)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, fiber.Map{"recoveryCodes": codes})
+}
+
+// VerifyMfa 登录第二步：使用 mfa pending token 和验证码或恢复码换取正式 token
+func VerifyMfa(c fiber.Ctx) error {
+	var req VerifyMfaRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	pair, err := service.CompleteMfaLogin(req.MfaToken, req.Code, service.ClientInfoFrom(c))
+	if err != nil {
+		var locked *service.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			return tooManyLoginAttempts(locked.RetryAfter)
+		case errors.Is(err, service.ErrMfaPendingInvalid):
+			return errMfaPendingInvalid
+		case errors.Is(err, service.ErrMfaTooManyAttempts):
+			return errMfaTooManyAttempts
+		}
+		return mfaError(err)
+	}
+	return respondTokens(c, pair)
+}
+
+func mfaError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrMfaCodeInvalid):
+		return errMfaCodeInvalid
+	case errors.Is(err, service.ErrMfaNotEnrolled):
+		return errMfaNotEnrolled
+	case errors.Is(err, service.ErrMfaAlreadyEnabled):
+		return errMfaAlreadyEnabled
+	case errors.Is(err, service.ErrMfaRequiredByRole):
+		return errMfaRequiredByRole
+	}
+	return apperror.Internal(err)
+}
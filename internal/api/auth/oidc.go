@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"errors"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/oidc"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+type oidcProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// OidcProviders 返回已配置的第三方登录方式，供登录页展示
+func OidcProviders(c fiber.Ctx) error {
+	providers := make([]oidcProviderResponse, 0, len(config.Current.Oidc.Providers))
+	for _, provider := range config.Current.Oidc.Providers {
+		displayName := provider.DisplayName
+		if displayName == "" {
+			displayName = provider.Name
+		}
+		providers = append(providers, oidcProviderResponse{Name: provider.Name, DisplayName: displayName})
+	}
+	return response.Success(c, providers)
+}
+
+// OidcLogin 跳转到身份提供方登录，登录状态写入仅回调路径可见的 HttpOnly cookie
+func OidcLogin(c fiber.Ctx) error {
+	authURL, stateCookie, err := service.StartOidcLogin(c.Context(), c.Params("provider"))
+	if err != nil {
+		if errors.Is(err, oidc.ErrUnknownProvider) {
+			return errOidcProviderUnknown
+		}
+		return apperror.Internal(err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    stateCookie,
+		Path:     oidcCookiePath,
+		MaxAge:   int(service.OidcStateTTL.Seconds()),
+		Secure:   config.IsProduction,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect().Status(fiber.StatusFound).To(authURL)
+}
+
+// OidcCallback 身份提供方回调，校验后按普通登录流程签发 token
+func OidcCallback(c fiber.Ctx) error {
+	stateCookie := c.Cookies(oidcStateCookie)
+	// 登录状态只能使用一次
+	c.Cookie(&fiber.Cookie{Name: oidcStateCookie, Path: oidcCookiePath, MaxAge: -1, HTTPOnly: true})
+
+	if c.Query("error") != "" {
+		return errOidcCancelled
+	}
+
+	user, err := service.CompleteOidcLogin(c.Context(), c.Params("provider"), c.Query("code"), c.Query("state"), stateCookie)
+	if err != nil {
+		switch {
+		case errors.Is(err, oidc.ErrUnknownProvider):
+			return errOidcProviderUnknown
+		case errors.Is(err, service.ErrOidcStateInvalid):
+			return errOidcStateInvalid
+		case errors.Is(err, service.ErrOidcSignupDisabled):
+			return errOidcNotLinked
+		}
+		logger.Error("第三方登录失败: %v", err)
+		service.Audit(c, service.AuditEvent{Action: model.AuditLogin}.Failed("oidc:"+c.Params("provider")))
+		return errOidcLoginFailed.Wrap(err)
+	}
+
+	if !user.IsActive() {
+		return inactiveUser(c, &user)
+	}
+	return issueLoginResponse(c, &user, "oidc:"+c.Params("provider"))
+}
+
+// ListIdentities 查询当前用户关联的第三方账号
+func ListIdentities(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	identities, err := db.ListUserIdentities(user.Id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, identities)
+}
+
+// DeleteIdentity 解除第三方账号关联，没有本地密码时不能解除最后一个关联
+func DeleteIdentity(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if !user.HasPassword() {
+		identities, err := db.ListUserIdentities(user.Id)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if len(identities) <= 1 {
+			return errLastIdentity
+		}
+	}
+
+	deleted, err := db.DeleteUserIdentity(user.Id, c.Params("id"))
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !deleted {
+		return errIdentityNotFound
+	}
+	return response.Success(c, nil)
+}
@@ -8,16 +8,47 @@
 package auth
 
 import (
+	"go-fiber-starter/internal/middleware"
+
 	"github.com/gofiber/fiber/v3"
 )
 
 func RegisterUnProtectedRoutes(router *fiber.App) {
+	router.Get("/.well-known/jwks.json", JWKS)
+
 	grp := router.Group("/api/auth")
 	grp.Post("/register", Register)
 	grp.Post("/login", Login)
+	grp.Post("/refresh", Refresh)
+	grp.Post("/email/verify", VerifyEmail)
+	grp.Post("/password/forgot", ForgotPassword)
+	grp.Post("/password/reset", ResetPassword)
+	grp.Post("/mfa/verify", VerifyMfa)
+	grp.Get("/oidc/providers", OidcProviders)
+	grp.Get("/oidc/:provider/login", OidcLogin)
+	grp.Get("/oidc/:provider/callback", OidcCallback)
 }
 
 func RegisterRoutes(router fiber.Router) {
-	grp := router.Group("/auth")
+	grp := router.Group("/auth", middleware.UserOnly())
 	grp.Get("/profile", Profile)
+	grp.Patch("/profile", middleware.SessionOnly(), UpdateProfile)
+	grp.Put("/password", middleware.SessionOnly(), ChangePassword)
+	grp.Delete("/account", middleware.SessionOnly(), DeleteAccount)
+	grp.Post("/email/resend", ResendVerification)
+	grp.Post("/logout", middleware.SessionOnly(), Logout)
+	grp.Post("/logout-all", middleware.SessionOnly(), LogoutAll)
+	grp.Get("/sessions", middleware.SessionOnly(), ListSessions)
+	grp.Delete("/sessions/:id", middleware.SessionOnly(), DeleteSession)
+	grp.Get("/mfa", MfaStatus)
+	grp.Post("/mfa/enroll", middleware.SessionOnly(), EnrollMfa)
+	grp.Post("/mfa/confirm", middleware.SessionOnly(), ConfirmMfa)
+	grp.Post("/mfa/disable", middleware.SessionOnly(), DisableMfa)
+	grp.Post("/mfa/recovery-codes", middleware.SessionOnly(), RegenerateRecoveryCodes)
+	grp.Get("/identities", ListIdentities)
+	grp.Delete("/identities/:id", middleware.SessionOnly(), DeleteIdentity)
+	grp.Get("/tokens", middleware.SessionOnly(), ListApiKeys)
+	grp.Post("/tokens", middleware.SessionOnly(), CreateApiKey)
+	grp.Delete("/tokens/:id", middleware.SessionOnly(), RevokeApiKey)
+	grp.Post("/impersonation/stop", StopImpersonation)
 }
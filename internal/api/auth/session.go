@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"errors"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type sessionResponse struct {
+	model.Session
+	// Current 是否为发起本次请求的会话
+	Current bool `json:"current"`
+}
+
+// ListSessions 查询当前用户已登录的设备
+func ListSessions(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := service.ListSessions(user.Id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	currentId := service.PrincipalFrom(c).SessionId
+	items := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		if session.Device == "" {
+			session.Device = response.T(c, "auth.unknown_device")
+		}
+		items[i] = sessionResponse{Session: session, Current: session.Id.String() == currentId}
+	}
+	return response.Success(c, items)
+}
+
+// DeleteSession 让指定设备下线，该会话的 token 立即失效
+func DeleteSession(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err := service.RevokeUserSession(user.Id, c.Params("id")); err != nil {
+		if errors.Is(err, service.ErrSessionNotFound) {
+			return errSessionNotFound
+		}
+		return apperror.Internal(err)
+	}
+	service.Audit(c, service.AuditEvent{Action: model.AuditSessionRevoke, TargetType: "session", TargetId: c.Params("id")})
+	return response.Success(c, nil)
+}
@@ -0,0 +1,97 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const grantClientCredentials = "client_credentials"
+
+// tokenError RFC 6749 5.2 定义的错误响应
+type tokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// Token OAuth2 token 端点，目前只支持 client_credentials 授权。
+// 客户端凭证可以放在 HTTP Basic 认证头或表单的 client_id/client_secret 中；
+// 为兼容标准 OAuth2 客户端库，响应按 RFC 6749 格式返回，不使用统一响应结构。
+func Token(c fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	if grantType := c.FormValue("grant_type"); grantType != grantClientCredentials {
+		return respondError(c, fiber.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+	}
+
+	clientId, secret, fromHeader, ok := clientCredentials(c)
+	if !ok {
+		return respondError(c, fiber.StatusBadRequest, "invalid_request", "malformed client credentials")
+	}
+	event := service.AuditEvent{Action: model.AuditClientToken, ActorName: clientId}
+
+	client, err := service.AuthenticateClient(clientId, secret)
+	if err != nil {
+		if !errors.Is(err, service.ErrClientInvalid) {
+			logger.Error("机器客户端认证失败: %v", err)
+			return respondError(c, fiber.StatusInternalServerError, "server_error", "")
+		}
+		service.Audit(c, event.Failed("invalid_client"))
+		if fromHeader {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+		}
+		return respondError(c, fiber.StatusUnauthorized, "invalid_client", "client authentication failed")
+	}
+	event.ActorId = client.ClientId
+	event.ActorName = client.Name
+
+	token, err := service.IssueClientToken(client, strings.Fields(c.FormValue("scope")))
+	if err != nil {
+		if errors.Is(err, service.ErrClientScope) {
+			service.Audit(c, event.Failed("invalid_scope"))
+			return respondError(c, fiber.StatusBadRequest, "invalid_scope", "requested scope is not allowed for this client")
+		}
+		logger.Error("签发机器客户端token失败: %v", err)
+		return respondError(c, fiber.StatusInternalServerError, "server_error", "")
+	}
+	event.Detail = "scope=" + token.Scope
+	service.Audit(c, event)
+
+	return c.JSON(token)
+}
+
+// clientCredentials 优先读取 HTTP Basic 认证头，按 RFC 6749 2.3.1 对 id 和 secret 做表单解码；
+// 认证头格式错误时 ok 为 false
+func clientCredentials(c fiber.Ctx) (clientId string, secret string, fromHeader bool, ok bool) {
+	authorization := c.Get(fiber.HeaderAuthorization)
+	if len(authorization) > 6 && strings.EqualFold(authorization[:6], "Basic ") {
+		decoded, err := base64.StdEncoding.DecodeString(authorization[6:])
+		if err != nil {
+			return "", "", true, false
+		}
+		rawId, rawSecret, found := strings.Cut(string(decoded), ":")
+		if !found {
+			return "", "", true, false
+		}
+		if clientId, err = url.QueryUnescape(rawId); err != nil {
+			return "", "", true, false
+		}
+		if secret, err = url.QueryUnescape(rawSecret); err != nil {
+			return "", "", true, false
+		}
+		return clientId, secret, true, true
+	}
+	return c.FormValue("client_id"), c.FormValue("client_secret"), false, true
+}
+
+func respondError(c fiber.Ctx, status int, code string, description string) error {
+	return c.Status(status).JSON(tokenError{Error: code, ErrorDescription: description})
+}
@@ -0,0 +1,230 @@
+package oauth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+
+	"go-fiber-starter/internal/api/admin"
+	"go-fiber-starter/internal/api/auth"
+	"go-fiber-starter/internal/middleware"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/validate"
+)
+
+type responseEnvelope struct {
+	Flag bool            `json:"flag"`
+	Code int             `json:"code"`
+	Data json.RawMessage `json:"data"`
+	Msg  string          `json:"msg"`
+}
+
+func setupTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	prevConfig := config.Current
+	config.Current.Jwt.Secret = "test-secret"
+	config.Current.App.Env = "test"
+
+	prevDB := db.DB
+	gormDB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(gormDB); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	if err := db.Seed(gormDB); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	db.DB = gormDB
+
+	t.Cleanup(func() {
+		service.WaitAudit()
+		_ = sqlDB.Close()
+		config.Current = prevConfig
+		db.DB = prevDB
+	})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler, StructValidator: validate.Default})
+	RegisterRoutes(app)
+	api := app.Group("/api")
+	api.Use(middleware.Auth())
+	api.Use(middleware.Tenant())
+	auth.RegisterRoutes(api)
+	admin.RegisterRoutes(api)
+
+	return app
+}
+
+// createClient 以 admin 身份注册机器客户端
+func createClient(t *testing.T, scopes ...string) service.CreatedClient {
+	t.Helper()
+
+	owner := &model.User{Username: "root", Password: "x"}
+	if err := db.DB.Create(owner).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	roles, err := db.GetRolesByNames([]string{model.RoleAdmin})
+	if err != nil {
+		t.Fatalf("get roles: %v", err)
+	}
+	if err := db.AppendUserRoles(owner, roles); err != nil {
+		t.Fatalf("append roles: %v", err)
+	}
+	loaded, err := db.GetUserById(owner.Id.String())
+	if err != nil {
+		t.Fatalf("load user: %v", err)
+	}
+
+	created, err := service.CreateClient(&loaded, "billing", scopes)
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	return created
+}
+
+func requestToken(t *testing.T, app *fiber.App, form url.Values, basic ...string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if len(basic) == 2 {
+		credentials := url.QueryEscape(basic[0]) + ":" + url.QueryEscape(basic[1])
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	return resp
+}
+
+func decodeJSON(t *testing.T, resp *http.Response, v interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path string, token string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	return resp
+}
+
+func TestClientCredentialsGrant(t *testing.T) {
+	app := setupTestApp(t)
+	created := createClient(t, model.PermissionUserRead, model.PermissionAuditRead)
+	if !strings.HasPrefix(created.Client.ClientId, model.ClientIdPrefix) || !strings.HasPrefix(created.ClientSecret, model.ClientSecretPrefix) {
+		t.Fatalf("unexpected client credentials: %+v", created)
+	}
+
+	// 表单和 Basic 认证头两种方式传递凭证
+	var token service.ClientToken
+	resp := requestToken(t, app, url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {created.Client.ClientId},
+		"client_secret": {created.ClientSecret},
+	})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+		t.Fatalf("unexpected token response: %d", resp.StatusCode)
+	}
+	decodeJSON(t, resp, &token)
+	if token.TokenType != "Bearer" || token.Scope != "user:read audit:read" {
+		t.Fatalf("unexpected token: %+v", token)
+	}
+
+	resp = requestToken(t, app, url.Values{"grant_type": {"client_credentials"}, "scope": {model.PermissionUserRead}},
+		created.Client.ClientId, created.ClientSecret)
+	decodeJSON(t, resp, &token)
+	if token.Scope != model.PermissionUserRead {
+		t.Fatalf("expected narrowed scope, got %q", token.Scope)
+	}
+
+	// 只能访问授予的 scope 对应的接口
+	var envelope responseEnvelope
+	decodeJSON(t, doRequest(t, app, http.MethodGet, "/api/admin/users", token.AccessToken), &envelope)
+	if !envelope.Flag {
+		t.Fatalf("expected client to list users, got %s", envelope.Msg)
+	}
+	decodeJSON(t, doRequest(t, app, http.MethodGet, "/api/admin/audit-logs", token.AccessToken), &envelope)
+	if envelope.Flag || envelope.Code != http.StatusForbidden {
+		t.Fatalf("expected audit log access outside token scope to be rejected, got %+v", envelope)
+	}
+	decodeJSON(t, doRequest(t, app, http.MethodGet, "/api/auth/profile", token.AccessToken), &envelope)
+	if envelope.Flag || envelope.Code != http.StatusForbidden {
+		t.Fatalf("expected machine client to be rejected from user routes, got %+v", envelope)
+	}
+
+	// 吊销后已签发的 token 立即失效
+	if _, err := service.RevokeClient(created.Client.Id.String()); err != nil {
+		t.Fatalf("revoke client: %v", err)
+	}
+	resp = doRequest(t, app, http.MethodGet, "/api/admin/users", token.AccessToken)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected revoked client token to be rejected, got %d", resp.StatusCode)
+	}
+	service.WaitAudit()
+
+	var logs []model.AuditLog
+	if err := db.DB.Where("action = ?", model.AuditClientToken).Find(&logs).Error; err != nil {
+		t.Fatalf("query audit logs: %v", err)
+	}
+	if len(logs) != 2 || logs[0].ActorId != created.Client.ClientId || logs[0].Outcome != model.AuditSuccess {
+		t.Fatalf("unexpected token audit logs: %+v", logs)
+	}
+}
+
+func TestClientCredentialsErrors(t *testing.T) {
+	app := setupTestApp(t)
+	created := createClient(t, model.PermissionUserRead)
+
+	cases := []struct {
+		name   string
+		form   url.Values
+		basic  []string
+		status int
+		code   string
+	}{
+		{"unsupported grant", url.Values{"grant_type": {"password"}}, nil, http.StatusBadRequest, "unsupported_grant_type"},
+		{"wrong secret", url.Values{"grant_type": {"client_credentials"}}, []string{created.Client.ClientId, "gfcs_wrong"}, http.StatusUnauthorized, "invalid_client"},
+		{"unknown client", url.Values{"grant_type": {"client_credentials"}, "client_id": {"gfc_unknown"}, "client_secret": {created.ClientSecret}}, nil, http.StatusUnauthorized, "invalid_client"},
+		{"scope not allowed", url.Values{"grant_type": {"client_credentials"}, "scope": {model.PermissionUserWrite}}, []string{created.Client.ClientId, created.ClientSecret}, http.StatusBadRequest, "invalid_scope"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := requestToken(t, app, tc.form, tc.basic...)
+			var body struct {
+				Error string `json:"error"`
+			}
+			status := resp.StatusCode
+			decodeJSON(t, resp, &body)
+			if status != tc.status || body.Error != tc.code {
+				t.Fatalf("expected %d %s, got %d %s", tc.status, tc.code, status, body.Error)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package oauth
+
+import "github.com/gofiber/fiber/v3"
+
+// RegisterRoutes 注册 OAuth2 授权服务端点，客户端通过 client_id 与 client_secret 认证，不经过 Auth 中间件
+func RegisterRoutes(router *fiber.App) {
+	router.Post("/oauth/token", Token)
+}
@@ -0,0 +1,18 @@
+package org
+
+// CreateOrganizationRequest 创建组织，slug 会转为小写，只能包含小写字母、数字和短横线
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=128" label:"field.organizationName"`
+	Slug string `json:"slug" validate:"required,max=63" label:"field.slug"`
+}
+
+// AddMemberRequest 按用户名添加成员，role 为空时为 member
+type AddMemberRequest struct {
+	Username string `json:"username" validate:"required" label:"field.username"`
+	Role     string `json:"role" validate:"oneof=owner admin member" label:"field.orgRole"`
+}
+
+// UpdateMemberRequest 修改成员在组织内的角色
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member" label:"field.orgRole"`
+}
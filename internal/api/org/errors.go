@@ -0,0 +1,39 @@
+package org
+
+import (
+	"errors"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// 组织接口返回的应用错误，错误码对外稳定，客户端可以据此区分失败原因；提示为语言包中的 key
+var (
+	errBadRequest       = apperror.Validation(apperror.CodeBadRequest, "error.bad_request")
+	errUserNotFound     = apperror.NotFound("user_not_found", "auth.user_not_found")
+	errOrgNotFound      = apperror.NotFound("organization_not_found", "org.not_found")
+	errOrgNameRequired  = apperror.Validation("organization_name_required", "org.name_required")
+	errOrgSlugInvalid   = apperror.Validation("organization_slug_invalid", "org.slug_invalid")
+	errOrgSlugTaken     = apperror.Validation("organization_slug_taken", "org.slug_taken")
+	errMemberExists     = apperror.Validation("member_exists", "org.member_exists")
+	errMemberNotFound   = apperror.NotFound("member_not_found", "org.member_not_found")
+	errOrgRoleInvalid   = apperror.Validation("organization_role_invalid", "org.role_invalid")
+	errOrgOwnerRequired = apperror.Forbidden("owner_required", "org.owner_required")
+	errOrgLastOwner     = apperror.Validation("last_owner", "org.last_owner")
+)
+
+// currentUser 读取当前登录用户，用户已被删除、禁用或调用方不是用户时返回 404，其余错误按服务端错误处理
+func currentUser(c fiber.Ctx) (*model.User, error) {
+	user, err := service.CurrentUser(c)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, service.ErrUserDisabled) || errors.Is(err, service.ErrNotUser) {
+			return nil, errUserNotFound.Wrap(err)
+		}
+		return nil, apperror.Internal(err)
+	}
+	return user, nil
+}
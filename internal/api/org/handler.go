@@ -0,0 +1,158 @@
+package org
+
+import (
+	"errors"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListOrganizations 查询当前用户加入的组织
+func ListOrganizations(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	memberships, err := db.ListUserMemberships(user.Id)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, memberships)
+}
+
+// CreateOrganization 创建组织，创建者成为 owner
+func CreateOrganization(c fiber.Ctx) error {
+	var req CreateOrganizationRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+
+	membership, err := service.CreateOrganization(user.Id, req.Name, req.Slug)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrgNameRequired):
+			return errOrgNameRequired
+		case errors.Is(err, service.ErrOrgSlugInvalid):
+			return errOrgSlugInvalid
+		case errors.Is(err, service.ErrOrgSlugTaken):
+			return errOrgSlugTaken
+		}
+		return apperror.Internal(err)
+	}
+	return response.Success(c, membership)
+}
+
+// SetDefaultOrganization 切换默认组织，刷新 token 后 tid 声明随之更新
+func SetDefaultOrganization(c fiber.Ctx) error {
+	user, err := currentUser(c)
+	if err != nil {
+		return err
+	}
+	organizationId, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return errOrgNotFound
+	}
+
+	if err := service.SetDefaultOrganization(user.Id, organizationId); err != nil {
+		if errors.Is(err, service.ErrOrgNotMember) {
+			return errOrgNotFound
+		}
+		return apperror.Internal(err)
+	}
+	return response.Success(c, nil)
+}
+
+// CurrentOrganization 查询当前请求所在的组织
+func CurrentOrganization(c fiber.Ctx) error {
+	organization, err := db.GetOrganization(service.PrincipalFrom(c).TenantId)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, organization)
+}
+
+func ListMembers(c fiber.Ctx) error {
+	members, err := db.ListOrganizationMembers(service.PrincipalFrom(c).TenantId)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	return response.Success(c, members)
+}
+
+// AddMember 按用户名把已注册用户加入当前组织
+func AddMember(c fiber.Ctx) error {
+	var req AddMemberRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+	if req.Role == "" {
+		req.Role = model.OrgRoleMember
+	}
+
+	membership, err := service.AddOrganizationMember(currentMembership(c), req.Username, req.Role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errUserNotFound.Wrap(err)
+		}
+		if errors.Is(err, service.ErrOrgMemberExists) {
+			return errMemberExists
+		}
+		return memberError(err)
+	}
+	return response.Success(c, membership)
+}
+
+// UpdateMember 修改成员在当前组织内的角色
+func UpdateMember(c fiber.Ctx) error {
+	var req UpdateMemberRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return errBadRequest.Wrap(err)
+	}
+
+	membership, err := service.UpdateOrganizationMemberRole(currentMembership(c), c.Params("userId"), req.Role)
+	if err != nil {
+		return memberError(err)
+	}
+	return response.Success(c, membership)
+}
+
+// RemoveMember 把成员移出当前组织
+func RemoveMember(c fiber.Ctx) error {
+	if err := service.RemoveOrganizationMember(currentMembership(c), c.Params("userId")); err != nil {
+		return memberError(err)
+	}
+	return response.Success(c, nil)
+}
+
+// currentMembership 调用方在当前组织内的成员关系，由 Tenant 中间件解析
+func currentMembership(c fiber.Ctx) model.Membership {
+	principal := service.PrincipalFrom(c)
+	return model.Membership{OrganizationId: principal.TenantId, Role: principal.TenantRole}
+}
+
+func memberError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrOrgNotMember):
+		return errMemberNotFound
+	case errors.Is(err, service.ErrOrgRoleInvalid):
+		return errOrgRoleInvalid
+	case errors.Is(err, service.ErrOrgOwnerRequired):
+		return errOrgOwnerRequired
+	case errors.Is(err, service.ErrOrgLastOwner):
+		return errOrgLastOwner
+	}
+	return apperror.Internal(err)
+}
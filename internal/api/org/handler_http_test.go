@@ -0,0 +1,239 @@
+package org
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+
+	"go-fiber-starter/internal/middleware"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/validate"
+)
+
+type responseEnvelope struct {
+	Flag  bool            `json:"flag"`
+	Code  int             `json:"code"`
+	Error string          `json:"error"`
+	Data  json.RawMessage `json:"data"`
+	Msg   string          `json:"msg"`
+}
+
+func setupTestApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	prevConfig := config.Current
+	config.Current.Jwt.Secret = "test-secret"
+	config.Current.Jwt.Expiration = 3600
+	config.Current.App.Env = "test"
+
+	prevDB := db.DB
+	gormDB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.RegisterTenantScope(gormDB); err != nil {
+		t.Fatalf("register tenant scope: %v", err)
+	}
+	if err := db.AutoMigrate(gormDB); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	if err := db.Seed(gormDB); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+	db.DB = gormDB
+
+	t.Cleanup(func() {
+		service.WaitAudit()
+		_ = sqlDB.Close()
+		config.Current = prevConfig
+		db.DB = prevDB
+	})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.ErrorHandler, StructValidator: validate.Default})
+	api := app.Group("/api")
+	api.Use(middleware.Auth())
+	api.Use(middleware.Tenant())
+	RegisterRoutes(api)
+
+	return app
+}
+
+// createUser 直接写库创建用户
+func createUser(t *testing.T, username string) *model.User {
+	t.Helper()
+
+	user := &model.User{Username: username, Password: "x"}
+	if err := db.DB.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// issueToken 为用户签发 token，tid 声明取签发时的默认组织
+func issueToken(t *testing.T, user *model.User) string {
+	t.Helper()
+
+	pair, err := service.IssueTokenPair(user, service.ClientInfo{})
+	if err != nil {
+		t.Fatalf("issue token: %v", err)
+	}
+	return pair.Token
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path string, headers map[string]string, body ...interface{}) responseEnvelope {
+	t.Helper()
+
+	var reader io.Reader
+	if len(body) > 0 {
+		payload, err := json.Marshal(body[0])
+		if err != nil {
+			t.Fatalf("marshal body: %v", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope responseEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	return envelope
+}
+
+func bearer(token string, tenantId ...string) map[string]string {
+	headers := map[string]string{"Authorization": "Bearer " + token}
+	if len(tenantId) > 0 {
+		headers[middleware.TenantHeader] = tenantId[0]
+	}
+	return headers
+}
+
+func TestCreateOrganizationAndTenantClaim(t *testing.T) {
+	app := setupTestApp(t)
+	alice := createUser(t, "alice")
+	token := issueToken(t, alice)
+
+	missing := doRequest(t, app, http.MethodGet, "/api/orgs/current", bearer(token))
+	if missing.Flag || missing.Code != http.StatusBadRequest {
+		t.Fatalf("expected tenant to be required, got flag=%v code=%d", missing.Flag, missing.Code)
+	}
+
+	invalid := doRequest(t, app, http.MethodPost, "/api/orgs", bearer(token), fiber.Map{"name": "Acme", "slug": "Acme Inc"})
+	if invalid.Flag || invalid.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid slug to be rejected, got flag=%v code=%d", invalid.Flag, invalid.Code)
+	}
+
+	created := doRequest(t, app, http.MethodPost, "/api/orgs", bearer(token), fiber.Map{"name": "Acme", "slug": "acme"})
+	if !created.Flag {
+		t.Fatalf("create organization failed: %s", created.Msg)
+	}
+	var membership model.Membership
+	if err := json.Unmarshal(created.Data, &membership); err != nil {
+		t.Fatalf("decode membership: %v", err)
+	}
+	if membership.Role != model.OrgRoleOwner || !membership.IsDefault {
+		t.Fatalf("unexpected membership: %+v", membership)
+	}
+
+	// 新签发的 token 携带默认组织，不传请求头也能访问当前组织
+	current := doRequest(t, app, http.MethodGet, "/api/orgs/current", bearer(issueToken(t, alice)))
+	if !current.Flag {
+		t.Fatalf("get current organization failed: %s", current.Msg)
+	}
+	var organization model.Organization
+	if err := json.Unmarshal(current.Data, &organization); err != nil {
+		t.Fatalf("decode organization: %v", err)
+	}
+	if organization.Id != membership.OrganizationId || organization.Slug != "acme" {
+		t.Fatalf("unexpected organization: %+v", organization)
+	}
+
+	taken := doRequest(t, app, http.MethodPost, "/api/orgs", bearer(token), fiber.Map{"name": "Acme 2", "slug": "acme"})
+	if taken.Flag || taken.Code != http.StatusBadRequest {
+		t.Fatalf("expected duplicate slug to be rejected, got flag=%v code=%d", taken.Flag, taken.Code)
+	}
+}
+
+func TestTenantHeaderRequiresMembership(t *testing.T) {
+	app := setupTestApp(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	membership, err := service.CreateOrganization(alice.Id, "Acme", "acme")
+	if err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	aliceToken := issueToken(t, alice)
+	bobToken := issueToken(t, bob)
+	orgId := membership.OrganizationId.String()
+
+	forbidden := doRequest(t, app, http.MethodGet, "/api/orgs/current", bearer(bobToken, orgId))
+	if forbidden.Flag || forbidden.Code != http.StatusForbidden {
+		t.Fatalf("expected non member to be rejected, got flag=%v code=%d", forbidden.Flag, forbidden.Code)
+	}
+
+	invalidRole := doRequest(t, app, http.MethodPost, "/api/orgs/current/members", bearer(aliceToken), fiber.Map{"username": "bob", "role": "root"})
+	if invalidRole.Flag || invalidRole.Error != "validation_failed" || !bytes.Contains(invalidRole.Data, []byte(`"field":"role"`)) {
+		t.Fatalf("expected role to be validated, got error=%q data=%s", invalidRole.Error, invalidRole.Data)
+	}
+
+	added := doRequest(t, app, http.MethodPost, "/api/orgs/current/members", bearer(aliceToken), fiber.Map{"username": "bob"})
+	if !added.Flag {
+		t.Fatalf("add member failed: %s", added.Msg)
+	}
+
+	members := doRequest(t, app, http.MethodGet, "/api/orgs/current/members", bearer(bobToken, orgId))
+	if !members.Flag {
+		t.Fatalf("list members failed: %s", members.Msg)
+	}
+	var list []model.Membership
+	if err := json.Unmarshal(members.Data, &list); err != nil {
+		t.Fatalf("decode members: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("expected 2 members, got %d", len(list))
+	}
+
+	notAllowed := doRequest(t, app, http.MethodDelete, "/api/orgs/current/members/"+alice.Id.String(), bearer(bobToken, orgId))
+	if notAllowed.Flag || notAllowed.Code != http.StatusForbidden {
+		t.Fatalf("expected member to be unable to remove owner, got flag=%v code=%d", notAllowed.Flag, notAllowed.Code)
+	}
+
+	lastOwner := doRequest(t, app, http.MethodPatch, "/api/orgs/current/members/"+alice.Id.String(), bearer(aliceToken), fiber.Map{"role": model.OrgRoleMember})
+	if lastOwner.Flag || lastOwner.Code != http.StatusBadRequest {
+		t.Fatalf("expected last owner demotion to be rejected, got flag=%v code=%d", lastOwner.Flag, lastOwner.Code)
+	}
+
+	removed := doRequest(t, app, http.MethodDelete, "/api/orgs/current/members/"+bob.Id.String(), bearer(aliceToken))
+	if !removed.Flag {
+		t.Fatalf("remove member failed: %s", removed.Msg)
+	}
+	gone := doRequest(t, app, http.MethodGet, "/api/orgs/current", bearer(bobToken, orgId))
+	if gone.Flag || gone.Code != http.StatusForbidden {
+		t.Fatalf("expected removed member to be rejected, got flag=%v code=%d", gone.Flag, gone.Code)
+	}
+}
@@ -0,0 +1,23 @@
+package org
+
+import (
+	"go-fiber-starter/internal/middleware"
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RegisterRoutes 注册组织相关路由，需挂在 Auth 和 Tenant 中间件之后
+func RegisterRoutes(router fiber.Router) {
+	grp := router.Group("/orgs", middleware.UserOnly())
+	grp.Get("", ListOrganizations)
+	grp.Post("", middleware.SessionOnly(), CreateOrganization)
+	grp.Post("/:id/default", middleware.SessionOnly(), SetDefaultOrganization)
+
+	current := grp.Group("/current", middleware.RequireTenant())
+	current.Get("", CurrentOrganization)
+	current.Get("/members", ListMembers)
+	current.Post("/members", middleware.RequireTenant(model.OrgRoleOwner, model.OrgRoleAdmin), AddMember)
+	current.Patch("/members/:userId", middleware.RequireTenant(model.OrgRoleOwner, model.OrgRoleAdmin), UpdateMember)
+	current.Delete("/members/:userId", middleware.RequireTenant(model.OrgRoleOwner, model.OrgRoleAdmin), RemoveMember)
+}
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"go-fiber-starter/pkg/i18n"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	// LocaleQuery 通过查询参数指定语言，优先级最高
+	LocaleQuery = "lang"
+
+	userLocaleLocalsKey = "userLocale"
+)
+
+// SetUserLocale 记录当前用户偏好的语言，由 Auth 中间件在认证通过后调用
+func SetUserLocale(c fiber.Ctx, locale string) {
+	c.Locals(userLocaleLocalsKey, locale)
+}
+
+// Locale 当前请求使用的语言：lang 查询参数 > 用户偏好 > Accept-Language > 默认语言，不受支持的语言会被跳过
+func Locale(c fiber.Ctx) string {
+	if locale, ok := i18n.Current.Match(c.Query(LocaleQuery)); ok {
+		return locale
+	}
+	if preferred, _ := c.Locals(userLocaleLocalsKey).(string); preferred != "" {
+		if locale, ok := i18n.Current.Match(preferred); ok {
+			return locale
+		}
+	}
+	if locale, ok := i18n.Current.Match(c.Get(fiber.HeaderAcceptLanguage)); ok {
+		return locale
+	}
+	return i18n.Current.Default()
+}
+
+// T 按当前请求的语言翻译 key，未收录的 key 原样返回
+func T(c fiber.Ctx, key string, params ...i18n.Params) string {
+	return i18n.Current.T(Locale(c), key, params...)
+}
@@ -0,0 +1,28 @@
+package response
+
+import "go-fiber-starter/pkg/query"
+
+// Page 列表接口的分页数据；页码分页返回 page 与 pageSize，游标分页返回 limit 与 nextCursor，
+// 下一页请求时将 nextCursor 作为 after 参数传回
+type Page struct {
+	Items      interface{} `json:"items"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page,omitempty"`
+	PageSize   int         `json:"pageSize,omitempty"`
+	Limit      int         `json:"limit,omitempty"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	HasMore    bool        `json:"hasMore"`
+}
+
+// NewPage 由 query.Find 返回的分页信息生成列表数据
+func NewPage(items interface{}, info query.PageInfo) Page {
+	return Page{
+		Items:      items,
+		Total:      info.Total,
+		Page:       info.Page,
+		PageSize:   info.PageSize,
+		Limit:      info.Limit,
+		NextCursor: info.NextCursor,
+		HasMore:    info.HasMore,
+	}
+}
@@ -0,0 +1,77 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-fiber-starter/pkg/config"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	// ProblemContentType RFC 7807 错误响应的 Content-Type
+	ProblemContentType = "application/problem+json"
+	// ProblemTypeDefault 未指定 type 时使用，title 即为 HTTP 状态码的标准描述
+	ProblemTypeDefault = "about:blank"
+
+	modeLocalsKey = "responseMode"
+)
+
+// Problem RFC 7807 错误响应，Extensions 中的成员与标准成员平铺输出
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+}
+
+// MarshalJSON 标准成员优先，Extensions 中同名的成员会被忽略
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// SetMode 指定当前请求的响应模式，优先于配置中的 app.responseMode
+func SetMode(c fiber.Ctx, mode string) {
+	c.Locals(modeLocalsKey, mode)
+}
+
+// ProblemMode 当前请求是否使用真实 HTTP 状态码与 RFC 7807 错误响应
+func ProblemMode(c fiber.Ctx) bool {
+	if mode, ok := c.Locals(modeLocalsKey).(string); ok {
+		return mode == config.ResponseProblem
+	}
+	return config.Current.App.Responses() == config.ResponseProblem
+}
+
+// SendProblem 按 RFC 7807 返回错误，未指定的 type、title、instance 使用默认值
+func SendProblem(c fiber.Ctx, problem Problem) error {
+	if problem.Status == 0 {
+		problem.Status = fiber.StatusInternalServerError
+	}
+	if problem.Type == "" {
+		problem.Type = ProblemTypeDefault
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Instance == "" {
+		problem.Instance = c.Path()
+	}
+	return c.Status(problem.Status).JSON(problem, ProblemContentType)
+}
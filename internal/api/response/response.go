@@ -8,28 +8,34 @@
 package response
 
 import (
+	"math"
+	"strconv"
 	"time"
 
+	"go-fiber-starter/pkg/apperror"
+
 	"github.com/gofiber/fiber/v3"
 )
 
 // Response 定义统一API响应结构
 type Response struct {
-	Flag bool        `json:"flag"`
-	Code int         `json:"code"`
-	Data interface{} `json:"data,omitempty"`
-	Msg  string      `json:"msg,omitempty"`
-	Time string      `json:"time"`
+	Flag bool `json:"flag"`
+	Code int  `json:"code"`
+	// Error 机器可读的错误码，仅应用错误会返回
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+	Msg   string      `json:"msg,omitempty"`
+	Time  string      `json:"time"`
 }
 
-// Success 返回成功响应
+// Success 返回成功响应，problem 模式下 code 同时作为 HTTP 状态码
 func Success(c fiber.Ctx, data interface{}, code ...int) error {
 	statusCode := fiber.StatusOK
 	if len(code) > 0 {
 		statusCode = code[0]
 	}
 
-	return c.Status(fiber.StatusOK).JSON(Response{
+	return c.Status(httpStatus(c, statusCode)).JSON(Response{
 		Flag: true,
 		Code: statusCode,
 		Data: data,
@@ -37,16 +43,72 @@ func Success(c fiber.Ctx, data interface{}, code ...int) error {
 	})
 }
 
-// Error 返回错误响应
+// Error 返回错误响应，problem 模式下按 RFC 7807 返回，msg 作为 detail；
+// msg 可以是语言包中的 key，按当前请求的语言翻译，未收录的文本原样返回
 func Error(c fiber.Ctx, msg string, code ...int) error {
 	statusCode := fiber.StatusInternalServerError
 	if len(code) > 0 {
 		statusCode = code[0]
 	}
+	msg = T(c, msg)
+	if ProblemMode(c) {
+		return SendProblem(c, Problem{Status: statusCode, Detail: msg})
+	}
+	return c.Status(fiber.StatusOK).JSON(Response{
+		Flag: false,
+		Code: statusCode,
+		Msg:  msg,
+		Time: time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// ErrorWithData 返回带有附加数据的错误响应，例如逐条的校验失败原因；problem 模式下 data 作为 errors 扩展成员，msg 的处理与 Error 相同
+func ErrorWithData(c fiber.Ctx, msg string, data interface{}, code ...int) error {
+	statusCode := fiber.StatusInternalServerError
+	if len(code) > 0 {
+		statusCode = code[0]
+	}
+	msg = T(c, msg)
+	if ProblemMode(c) {
+		return SendProblem(c, Problem{Status: statusCode, Detail: msg, Extensions: map[string]interface{}{"errors": data}})
+	}
 	return c.Status(fiber.StatusOK).JSON(Response{
 		Flag: false,
 		Code: statusCode,
+		Data: data,
 		Msg:  msg,
 		Time: time.Now().UTC().Format(time.RFC3339Nano),
 	})
 }
+
+// Fail 返回应用错误，两种模式都会带上机器可读的错误码：legacy 模式为 error 字段，problem 模式为 code 扩展成员；
+// Details 分别作为 data 与 errors 扩展成员返回，Cause 不会出现在响应中；Message 与 Params 按当前请求的语言翻译
+func Fail(c fiber.Ctx, err *apperror.Error) error {
+	message := T(c, err.Message, err.Params)
+	if err.RetryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	}
+	if ProblemMode(c) {
+		extensions := map[string]interface{}{"code": err.Code}
+		if err.Details != nil {
+			extensions["errors"] = err.Details
+		}
+		return SendProblem(c, Problem{Status: err.Status, Detail: message, Extensions: extensions})
+	}
+	return c.Status(fiber.StatusOK).JSON(Response{
+		Flag:  false,
+		Code:  err.Status,
+		Error: err.Code,
+		Data:  err.Details,
+		Msg:   message,
+		Time:  time.Now().UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// httpStatus legacy 模式下 HTTP 状态码始终为 200
+func httpStatus(c fiber.Ctx, code int) int {
+	if ProblemMode(c) {
+		return code
+	}
+	return fiber.StatusOK
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"go-fiber-starter/internal/api/response"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/jwtkey"
+	"go-fiber-starter/pkg/logger"
+
+	jwtware "github.com/gofiber/contrib/v3/jwt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Auth 校验 JWT 签名与有效期并拒绝已被吊销的 token；以 gfs_ 开头的凭证按 API key 校验。
+// 启用 cookie 会话模式时，未携带 Bearer token 的请求从 cookie 读取 access token，写请求需通过 CSRF 校验
+func Auth() fiber.Handler {
+	extractor := extractors.FromAuthHeader("Bearer")
+	if service.CookieMode() {
+		extractor = extractors.Chain(extractor, extractors.FromCookie(config.Current.Cookie.AccessCookie()))
+	}
+
+	jwtHandler := jwtware.New(jwtware.Config{
+		Extractor: extractor,
+		// 每次按当前密钥集选择验签密钥，支持按 kid 轮换
+		KeyFunc: func(token *jwt.Token) (interface{}, error) {
+			return jwtkey.Active().Keyfunc(token)
+		},
+		SuccessHandler: func(c fiber.Ctx) error {
+			token := jwtware.FromContext(c)
+			if service.TokenType(token) == service.TokenTypeClient {
+				return authenticateClient(c, token)
+			}
+			// mfa pending 等非 access token 只能在各自的专用接口使用
+			if service.TokenType(token) != service.TokenTypeAccess {
+				return unauthorized(c)
+			}
+			if err := service.CheckTokenRevoked(token); err != nil {
+				if !errors.Is(err, service.ErrTokenRevoked) {
+					logger.Error("检查token吊销状态失败: %v", err)
+					return unauthorized(c)
+				}
+				service.Audit(c, service.AuditEvent{
+					Action:    model.AuditTokenInvalid,
+					ActorName: service.TokenUsername(token),
+				}.Failed("revoked"))
+				return unauthorized(c)
+			}
+			if service.TokenRequiresMfaSetup(token) && !mfaSetupAllowed(c.Path()) {
+				return response.Error(c, "auth.mfa_setup_required", fiber.StatusForbidden)
+			}
+
+			userId, err := service.CurrentUserId(token)
+			if err != nil {
+				return unauthorized(c)
+			}
+			sessionId := service.TokenSessionId(token)
+			principal := &service.Principal{
+				Kind:      service.PrincipalUser,
+				UserId:    userId,
+				Username:  service.TokenUsername(token),
+				Roles:     service.TokenRoles(token),
+				SessionId: sessionId,
+				Locale:    service.TokenLocale(token),
+			}
+			if actorId, actorName := service.TokenImpersonator(token); actorId != "" {
+				principal.Kind = service.PrincipalImpersonation
+				principal.ImpersonatorId = actorId
+				principal.ImpersonatorName = actorName
+			}
+			service.SetPrincipal(c, principal)
+			response.SetUserLocale(c, principal.Locale)
+			if principal.IsImpersonated() {
+				// 模拟登录期间的每个请求都记录审计日志
+				service.Audit(c, service.AuditEvent{
+					Action: model.AuditImpersonatedRequest,
+					Detail: c.Method() + " " + c.Path(),
+				})
+			}
+			service.TouchSession(sessionId, service.ClientInfoFrom(c))
+			return c.Next()
+		},
+		// 添加自定义错误处理，返回401状态码
+		ErrorHandler: func(c fiber.Ctx, err error) error {
+			logger.Error("JWT验证失败: %v", err)
+			// 未携带 token 的请求不记录，避免匿名访问刷满审计日志
+			if !errors.Is(err, extractors.ErrNotFound) {
+				service.Audit(c, service.AuditEvent{Action: model.AuditTokenInvalid}.Failed(err.Error()))
+			}
+			return unauthorized(c)
+		},
+	})
+
+	return func(c fiber.Ctx) error {
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			return authenticateApiKey(c, rawKey)
+		}
+		// 浏览器会自动携带 cookie，通过 cookie 认证的写请求必须证明能读取 CSRF cookie
+		if service.AccessTokenFromCookie(c) && !service.ValidCsrf(c) {
+			return response.Error(c, "auth.csrf_failed", fiber.StatusForbidden)
+		}
+		return jwtHandler(c)
+	}
+}
+
+// apiKeyFromRequest 从 X-API-Key 或 Authorization: Bearer 头中读取 API key
+func apiKeyFromRequest(c fiber.Ctx) string {
+	if rawKey := c.Get("X-API-Key"); rawKey != "" {
+		return rawKey
+	}
+	authorization := c.Get(fiber.HeaderAuthorization)
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") && service.IsApiKey(authorization[7:]) {
+		return authorization[7:]
+	}
+	return ""
+}
+
+func authenticateApiKey(c fiber.Ctx, rawKey string) error {
+	principal, err := service.AuthenticateApiKey(rawKey)
+	if err != nil {
+		if errors.Is(err, service.ErrMfaSetupRequired) {
+			return response.Error(c, "auth.mfa_setup_required", fiber.StatusForbidden)
+		}
+		if !errors.Is(err, service.ErrApiKeyInvalid) {
+			logger.Error("API key验证失败: %v", err)
+			return unauthorized(c)
+		}
+		service.Audit(c, service.AuditEvent{Action: model.AuditTokenInvalid}.Failed("api key invalid"))
+		return unauthorized(c)
+	}
+
+	service.SetPrincipal(c, principal)
+	response.SetUserLocale(c, principal.Locale)
+	return c.Next()
+}
+
+// authenticateClient 校验机器客户端 token，客户端被吊销后立即拒绝
+func authenticateClient(c fiber.Ctx, token *jwt.Token) error {
+	principal, err := service.AuthenticateClientToken(token)
+	if err != nil {
+		if !errors.Is(err, service.ErrClientInvalid) {
+			logger.Error("机器客户端token验证失败: %v", err)
+			return unauthorized(c)
+		}
+		service.Audit(c, service.AuditEvent{Action: model.AuditTokenInvalid}.Failed("client invalid"))
+		return unauthorized(c)
+	}
+
+	service.SetPrincipal(c, principal)
+	return c.Next()
+}
+
+// mfaSetupAllowedPaths 角色要求两步验证但尚未启用的用户可以访问的接口
+var mfaSetupAllowedPaths = []string{
+	"/api/auth/mfa",
+	"/api/auth/profile",
+	"/api/auth/logout",
+}
+
+func mfaSetupAllowed(path string) bool {
+	for _, prefix := range mfaSetupAllowedPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unauthorized 两种响应模式下都使用 401 状态码，legacy 模式保持原有的响应结构
+func unauthorized(c fiber.Ctx) error {
+	if response.ProblemMode(c) {
+		return response.Error(c, "auth.unauthorized", fiber.StatusUnauthorized)
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"code":    fiber.StatusUnauthorized,
+		"message": response.T(c, "auth.unauthorized"),
+	})
+}
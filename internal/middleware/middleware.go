@@ -8,11 +8,56 @@
 package middleware
 
 import (
+	"errors"
+	"net/http"
+
 	"go-fiber-starter/internal/api/response"
+	"go-fiber-starter/pkg/apperror"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/validate"
 
 	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
 )
 
+// ErrorHandler 将 handler 返回的错误统一转换为响应：validate.Errors 返回逐字段的校验失败原因，*apperror.Error 按自身的状态码与错误码返回，
+// *fiber.Error 与 gorm.ErrRecordNotFound 映射为对应的通用错误，其余错误一律视为 500；
+// 底层原因只写入服务端日志，不会返回给调用方
 func ErrorHandler(c fiber.Ctx, err error) error {
-	return response.Error(c, err.Error(), fiber.StatusInternalServerError)
+	appErr := toAppError(c, err)
+	if appErr.Status >= http.StatusInternalServerError {
+		logger.Error("请求处理失败 %s %s: %v", c.Method(), c.Path(), err)
+	} else if appErr.Cause != nil {
+		logger.Debug("请求处理失败 %s %s: %v", c.Method(), c.Path(), err)
+	}
+	return response.Fail(c, appErr)
+}
+
+func toAppError(c fiber.Ctx, err error) *apperror.Error {
+	// 绑定参数时的校验错误通常被包装成 400，这里优先返回逐字段的错误明细
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		fieldErrs = fieldErrs.Localize(response.Locale(c))
+		return apperror.Validation(apperror.CodeValidation, fieldErrs.Error()).WithDetails(fieldErrs).Wrap(err)
+	}
+	if appErr, ok := apperror.As(err); ok {
+		return appErr
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return apperror.FromStatus(fiberErr.Code, fiberErr.Message).Wrap(err)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.NotFound(apperror.CodeNotFound, "error.not_found").Wrap(err)
+	}
+	return apperror.Internal(err)
+}
+
+// ResponseMode 为路由组指定响应模式（config.ResponseLegacy/config.ResponseProblem），覆盖 app.responseMode；
+// 需要挂在 Auth 之前，认证失败的响应才会使用该模式
+func ResponseMode(mode string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		response.SetMode(c, mode)
+		return c.Next()
+	}
 }
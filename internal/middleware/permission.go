@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"go-fiber-starter/internal/api/response"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequirePermission 要求当前调用方的角色拥有全部指定权限，API key 还需被授予对应 scope，需挂在 Auth 之后
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := service.PrincipalFrom(c)
+		if principal == nil {
+			return unauthorized(c)
+		}
+
+		for _, permission := range permissions {
+			allowed, err := principal.HasPermission(permission)
+			if err != nil {
+				logger.Error("检查权限失败: %v", err)
+				return response.Error(c, "auth.permission_check_failed")
+			}
+			if !allowed {
+				return response.Error(c, "auth.forbidden", fiber.StatusForbidden)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// UserOnly 拒绝机器客户端访问，用于个人资料、组织等以用户身份为前提的接口
+func UserOnly() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := service.PrincipalFrom(c)
+		if principal == nil {
+			return unauthorized(c)
+		}
+		if principal.IsClient() {
+			return response.Error(c, "auth.client_forbidden", fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
+
+// SessionOnly 只允许通过登录获得的用户 token 访问，API key 和模拟登录 token 不能用于修改密码、管理凭证等敏感操作
+func SessionOnly() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := service.PrincipalFrom(c)
+		if principal == nil {
+			return unauthorized(c)
+		}
+		if principal.IsImpersonated() {
+			return response.Error(c, "auth.impersonation_forbidden", fiber.StatusForbidden)
+		}
+		if !principal.IsSession() {
+			return response.Error(c, "auth.session_required", fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
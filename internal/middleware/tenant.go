@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"errors"
+	"slices"
+
+	"go-fiber-starter/internal/api/response"
+	"go-fiber-starter/internal/service"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/tenant"
+
+	jwtware "github.com/gofiber/contrib/v3/jwt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// TenantHeader 指定当前请求所在组织的请求头，优先于 token 中的 tid 声明
+const TenantHeader = "X-Tenant-Id"
+
+// Tenant 解析当前请求所在的组织并校验成员关系，需挂在 Auth 之后。
+// 组织写入请求 context，通过 db.Tenant(c.Context()) 访问多租户模型时自动限定在该组织内；
+// 未选定组织时多租户模型的读写会被拒绝。
+func Tenant() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := service.PrincipalFrom(c)
+		if principal == nil {
+			return unauthorized(c)
+		}
+
+		raw := c.Get(TenantHeader)
+		fromHeader := raw != ""
+		if !fromHeader {
+			raw = service.TokenTenantId(jwtware.FromContext(c))
+		}
+		if raw == "" {
+			return c.Next()
+		}
+
+		organizationId, err := uuid.Parse(raw)
+		if err != nil {
+			return response.Error(c, "org.invalid_id", fiber.StatusBadRequest)
+		}
+		membership, err := service.ResolveMembership(principal.UserId, organizationId)
+		if err != nil {
+			if !errors.Is(err, service.ErrOrgNotMember) {
+				logger.Error("查询组织成员关系失败: %v", err)
+				return response.Error(c, "org.lookup_failed")
+			}
+			// token 签发后被移出默认组织时忽略 tid，显式指定的组织则直接拒绝
+			if fromHeader {
+				return response.Error(c, "org.not_member", fiber.StatusForbidden)
+			}
+			return c.Next()
+		}
+
+		principal.TenantId = organizationId
+		principal.TenantRole = membership.Role
+		c.SetContext(tenant.WithTenant(c.Context(), organizationId))
+		return c.Next()
+	}
+}
+
+// RequireTenant 要求当前请求已选定组织，传入角色时还要求调用方在该组织内拥有其中之一，需挂在 Tenant 之后
+func RequireTenant(roles ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		principal := service.PrincipalFrom(c)
+		if principal == nil {
+			return unauthorized(c)
+		}
+		if !principal.HasTenant() {
+			return response.Error(c, "org.required", fiber.StatusBadRequest)
+		}
+		if len(roles) > 0 && !slices.Contains(roles, principal.TenantRole) {
+			return response.Error(c, "auth.forbidden", fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
+
+// CrossTenant 显式允许后续处理跨租户访问多租户模型，只用于已经过权限校验的管理后台路由
+func CrossTenant() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.SetContext(tenant.WithCrossTenant(c.Context()))
+		return c.Next()
+	}
+}
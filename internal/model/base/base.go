@@ -15,6 +15,9 @@ type BaseModel struct {
 
 func (base *BaseModel) BeforeCreate(tx *gorm.DB) (err error) {
 	// 创建记录前生成主键，兼容 SQLite、PostgreSQL 和 MySQL
-	base.Id = uuid.New()
+	// 已有主键时保留，避免关联写入已存在的记录时主键被覆盖
+	if base.Id == uuid.Nil {
+		base.Id = uuid.New()
+	}
 	return
 }
@@ -0,0 +1,12 @@
+package base
+
+import "github.com/google/uuid"
+
+// TenantModel 多租户模型的基类，嵌入后通过 db.DB 的读写都会自动限定在当前组织内
+type TenantModel struct {
+	BaseModel
+	OrganizationId uuid.UUID `gorm:"type:char(36);index;not null" json:"organizationId"`
+}
+
+// TenantScoped 标记该模型按组织隔离
+func (TenantModel) TenantScoped() {}
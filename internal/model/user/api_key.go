@@ -0,0 +1,30 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// ApiKeyPrefix API key 的固定前缀，便于密钥扫描工具识别泄露
+const ApiKeyPrefix = "gfs_"
+
+// ApiKey 用户为脚本、CI 创建的长期凭证，只保存 SHA-256 摘要，明文仅在创建时返回一次
+type ApiKey struct {
+	base.BaseModel
+	UserId     uuid.UUID  `gorm:"type:char(36);index" json:"userId"`
+	Name       string     `gorm:"size:64" json:"name" example:"ci"`
+	Prefix     string     `gorm:"size:16" json:"prefix" example:"gfs_AbCd1234"` // 明文前几位，用于在列表中辨认
+	KeyHash    string     `gorm:"uniqueIndex;size:64" json:"-"`
+	Scopes     []string   `gorm:"serializer:json;size:1024" json:"scopes"` // 可使用的权限，为空表示只能访问无需权限的接口
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// IsUsable 未吊销且未过期
+func (k *ApiKey) IsUsable(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
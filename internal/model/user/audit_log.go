@@ -0,0 +1,74 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+)
+
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// 审计事件类型
+const (
+	AuditLogin            = "auth.login"
+	AuditMfaVerify        = "auth.mfa_verify"
+	AuditRegister         = "auth.register"
+	AuditLogout           = "auth.logout"
+	AuditLogoutAll        = "auth.logout_all"
+	AuditTokenRefresh     = "token.refresh"
+	AuditTokenInvalid     = "token.invalid"
+	AuditSessionRevoke    = "session.revoke"
+	AuditApiKeyCreate     = "api_key.create"
+	AuditApiKeyRevoke     = "api_key.revoke"
+	AuditPasswordChange   = "password.change"
+	AuditPasswordReset    = "password.reset"
+	AuditMfaEnable        = "mfa.enable"
+	AuditMfaDisable       = "mfa.disable"
+	AuditAccountDelete    = "account.delete"
+	AuditAdminUserUpdate  = "admin.user.update"
+	AuditAdminUserDisable = "admin.user.disable"
+	AuditAdminUserEnable  = "admin.user.enable"
+	AuditAdminUserApprove = "admin.user.approve"
+	AuditAdminUserReject  = "admin.user.reject"
+	AuditAdminUserDelete  = "admin.user.delete"
+	AuditAdminPassword    = "admin.user.password_reset"
+	AuditAdminRevoke      = "admin.user.revoke_tokens"
+	AuditAdminRoleUpdate  = "admin.role.update"
+	AuditAdminInviteAdd   = "admin.invite.create"
+	AuditAdminInviteDel   = "admin.invite.delete"
+	AuditAdminImpersonate = "admin.user.impersonate"
+	AuditAdminClientAdd   = "admin.client.create"
+	AuditAdminClientDel   = "admin.client.revoke"
+	// AuditClientToken 机器客户端通过 client credentials 换取 token
+	AuditClientToken = "oauth.token"
+	// AuditLoginLockout 登录失败次数达到阈值，账号或 IP 被临时锁定，detail 中记录 key、失败次数与解锁时间
+	AuditLoginLockout = "auth.login_lockout"
+	// AuditImpersonatedRequest 模拟登录期间的每个请求
+	AuditImpersonatedRequest = "impersonation.request"
+	AuditImpersonationStop   = "impersonation.stop"
+)
+
+// AuditLog 认证与管理操作的审计记录，只追加不修改
+type AuditLog struct {
+	base.BaseModel
+	// OccurredAt 事件发生时间，日志异步写入，CreatedAt 为落库时间
+	OccurredAt time.Time `gorm:"index" json:"occurredAt"`
+	Action     string    `gorm:"size:64;index" json:"action" example:"auth.login"`
+	Outcome    string    `gorm:"size:16;index" json:"outcome" example:"success"`
+	// ActorId 执行操作的用户，登录失败等无法确定身份时为空，ActorName 记录提交的用户名
+	ActorId   string `gorm:"size:36;index" json:"actorId"`
+	ActorName string `gorm:"size:128" json:"actorName"`
+	// ImpersonatorId 模拟登录期间发生的事件记录实际操作的管理员，Actor 为被模拟的用户
+	ImpersonatorId   string `gorm:"size:36;index" json:"impersonatorId,omitempty"`
+	ImpersonatorName string `gorm:"size:128" json:"impersonatorName,omitempty"`
+	TargetType       string `gorm:"size:32" json:"targetType" example:"user"`
+	TargetId         string `gorm:"size:64;index" json:"targetId"`
+	TargetName       string `gorm:"size:128" json:"targetName"`
+	Ip               string `gorm:"size:64;index" json:"ip"`
+	UserAgent        string `gorm:"size:512" json:"userAgent"`
+	// Detail 失败原因或操作的补充说明，不包含密码、token 等敏感信息
+	Detail string `gorm:"size:512" json:"detail"`
+}
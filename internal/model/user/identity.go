@@ -0,0 +1,19 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// Identity 用户在外部身份提供方（OIDC）上的身份，同一 provider 下 subject 唯一
+type Identity struct {
+	base.BaseModel
+	UserId      uuid.UUID  `gorm:"type:char(36);index" json:"userId"`
+	Provider    string     `gorm:"size:64;uniqueIndex:idx_identity_subject" json:"provider" example:"google"`
+	Subject     string     `gorm:"size:255;uniqueIndex:idx_identity_subject" json:"subject"`
+	Email       string     `gorm:"size:255" json:"email"`
+	LastLoginAt *time.Time `json:"lastLoginAt"`
+}
@@ -0,0 +1,26 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// Invite 管理员创建的一次性注册邀请码，只保存 SHA-256 摘要，明文仅在创建时返回一次
+type Invite struct {
+	base.BaseModel
+	CodeHash  string     `gorm:"uniqueIndex;size:64" json:"-"`
+	Role      string     `gorm:"size:64" json:"role" example:"admin"` // 注册时在默认角色之外额外授予的角色
+	Note      string     `gorm:"size:255" json:"note"`
+	CreatedBy uuid.UUID  `gorm:"type:char(36)" json:"createdBy"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	UsedBy    *uuid.UUID `gorm:"type:char(36)" json:"usedBy"`
+}
+
+// IsUsable 未使用且未过期
+func (i *Invite) IsUsable(now time.Time) bool {
+	return i.UsedAt == nil && (i.ExpiresAt == nil || now.Before(*i.ExpiresAt))
+}
@@ -0,0 +1,16 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+)
+
+// LoginAttempt 登录失败计数，key 为 "account:用户名" 或 "ip:地址"，多实例部署时共享锁定状态
+type LoginAttempt struct {
+	base.BaseModel
+	Key           string    `gorm:"column:attempt_key;uniqueIndex;size:191" json:"key"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"lastFailureAt"`
+	LockedUntil   time.Time `gorm:"index" json:"lockedUntil"`
+}
@@ -0,0 +1,31 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+)
+
+const (
+	// ClientIdPrefix 机器客户端 client_id 的固定前缀
+	ClientIdPrefix = "gfc_"
+	// ClientSecretPrefix client_secret 的固定前缀，便于密钥扫描工具识别泄露
+	ClientSecretPrefix = "gfcs_"
+)
+
+// OAuthClient 服务间调用使用的机器客户端，通过 client credentials 授权换取 access token；
+// 密钥只保存 SHA-256 摘要，明文仅在创建时返回一次
+type OAuthClient struct {
+	base.BaseModel
+	ClientId   string     `gorm:"uniqueIndex;size:64" json:"clientId" example:"gfc_AbCdEfGh12345678"`
+	Name       string     `gorm:"size:64" json:"name" example:"billing-service"`
+	SecretHash string     `gorm:"size:64" json:"-"`
+	Scopes     []string   `gorm:"serializer:json;size:1024" json:"scopes"` // 允许申请的权限，机器客户端没有角色，权限完全由 scope 决定
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
+
+// IsUsable 未吊销
+func (c *OAuthClient) IsUsable() bool {
+	return c.RevokedAt == nil
+}
@@ -0,0 +1,35 @@
+package user
+
+import (
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// 组织内角色，与系统角色相互独立
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+// OrgRoles 可分配的组织内角色
+var OrgRoles = []string{OrgRoleOwner, OrgRoleAdmin, OrgRoleMember}
+
+type Organization struct {
+	base.BaseModel
+	Name string `gorm:"size:128" json:"name" example:"Acme"`
+	Slug string `gorm:"uniqueIndex;size:64" json:"slug" example:"acme"`
+}
+
+// Membership 用户与组织的成员关系，一个用户可以加入多个组织
+type Membership struct {
+	base.BaseModel
+	OrganizationId uuid.UUID     `gorm:"type:char(36);uniqueIndex:idx_membership_org_user" json:"organizationId"`
+	UserId         uuid.UUID     `gorm:"type:char(36);uniqueIndex:idx_membership_org_user;index" json:"userId"`
+	Role           string        `gorm:"size:16" json:"role" example:"member"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationId" json:"organization,omitempty"`
+	User           *User         `gorm:"foreignKey:UserId" json:"user,omitempty"`
+	// IsDefault 登录时写入 token tid 声明的默认组织
+	IsDefault bool `gorm:"default:false" json:"isDefault"`
+}
@@ -0,0 +1,17 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// RecoveryCode 两步验证恢复码，只保存哈希，每个恢复码只能使用一次
+type RecoveryCode struct {
+	base.BaseModel
+	UserId   uuid.UUID  `gorm:"type:char(36);index" json:"userId"`
+	CodeHash string     `gorm:"size:64;index" json:"-"`
+	UsedAt   *time.Time `json:"usedAt"`
+}
@@ -0,0 +1,21 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken 持久化的 refresh token，数据库只保存 token 的 SHA-256 摘要
+type RefreshToken struct {
+	base.BaseModel
+	UserId     uuid.UUID  `gorm:"type:char(36);index" json:"userId"`
+	FamilyId   uuid.UUID  `gorm:"type:char(36);index" json:"familyId"` // 同一次登录轮换出来的 token 共用一个 family
+	TokenHash  string     `gorm:"uniqueIndex;size:64" json:"-"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	UsedAt     *time.Time `json:"usedAt"`    // 轮换后写入，再次出现即视为重放
+	RevokedAt  *time.Time `json:"revokedAt"` // 整个 family 被吊销时写入
+	ReplacedBy *uuid.UUID `gorm:"type:char(36)" json:"replacedBy"`
+}
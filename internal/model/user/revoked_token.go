@@ -0,0 +1,17 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// RevokedToken 被主动吊销的 access token，过期后即可清理
+type RevokedToken struct {
+	base.BaseModel
+	Jti       string    `gorm:"uniqueIndex;size:64" json:"jti"`
+	UserId    uuid.UUID `gorm:"type:char(36);index" json:"userId"`
+	ExpiresAt time.Time `gorm:"index" json:"expiresAt"`
+}
@@ -0,0 +1,47 @@
+package user
+
+import "go-fiber-starter/internal/model/base"
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+const (
+	PermissionUserRead     = "user:read"
+	PermissionUserWrite    = "user:write"
+	PermissionTokenRevoke  = "token:revoke"
+	PermissionRoleManage   = "role:manage"
+	PermissionInviteManage = "invite:manage"
+	PermissionAuditRead    = "audit:read"
+	// PermissionUserImpersonate 以其他用户身份登录，拥有该权限的用户不能被模拟
+	PermissionUserImpersonate = "user:impersonate"
+	PermissionClientManage    = "client:manage"
+)
+
+// Permissions 系统内置权限及说明，启动时同步到数据库，admin 角色拥有全部权限
+var Permissions = map[string]string{
+	PermissionUserRead:        "查看用户",
+	PermissionUserWrite:       "管理用户",
+	PermissionTokenRevoke:     "吊销用户token",
+	PermissionRoleManage:      "管理角色",
+	PermissionInviteManage:    "管理注册邀请码",
+	PermissionAuditRead:       "查看审计日志",
+	PermissionUserImpersonate: "模拟用户登录",
+	PermissionClientManage:    "管理机器客户端",
+}
+
+type Permission struct {
+	base.BaseModel
+	Code        string `gorm:"uniqueIndex;size:64" json:"code" example:"user:write"`
+	Description string `gorm:"size:255" json:"description"`
+}
+
+type Role struct {
+	base.BaseModel
+	Name        string       `gorm:"uniqueIndex;size:64" json:"name" example:"admin"`
+	Description string       `gorm:"size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions,omitempty"`
+	// RequireMfa 拥有该角色的用户必须启用两步验证，未启用前只能访问两步验证相关接口
+	RequireMfa bool `gorm:"default:false" json:"requireMfa"`
+}
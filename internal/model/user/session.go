@@ -0,0 +1,22 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+// Session 一次登录产生的会话，Id 与该次登录的 refresh token FamilyId 相同，access token 通过 sid 声明关联
+type Session struct {
+	base.BaseModel
+	UserId     uuid.UUID  `gorm:"type:char(36);index" json:"userId"`
+	Device     string     `gorm:"size:128" json:"device" example:"Chrome on macOS"` // 根据 User-Agent 得到的简短描述
+	UserAgent  string     `gorm:"size:512" json:"userAgent"`
+	Ip         string     `gorm:"size:64" json:"ip"`
+	Jti        string     `gorm:"size:64" json:"-"` // 最近一次签发的 access token
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expiresAt"` // 随 refresh token 轮换延长
+	RevokedAt  *time.Time `json:"revokedAt"`
+}
@@ -8,11 +8,49 @@
 package user
 
 import (
+	"time"
+
 	"go-fiber-starter/internal/model/base"
 )
 
+const (
+	StatusActive   = "active"
+	StatusDisabled = "disabled"
+	// StatusPending 审核注册模式下新注册的用户，管理员审核通过前不能登录
+	StatusPending = "pending"
+)
+
 type User struct {
 	base.BaseModel
 	Username string `gorm:"uniqueIndex;size:64" json:"username" example:"admin"`
 	Password string `json:"-" example:"123456"`
+	Status   string `gorm:"size:16;default:active;index" json:"status" example:"active"`
+	Roles    []Role `gorm:"many2many:user_roles" json:"roles,omitempty"`
+
+	DisplayName string `gorm:"size:64" json:"displayName" example:"管理员"`
+	Email       string `gorm:"size:255;index" json:"email" example:"admin@example.com"`
+	AvatarUrl   string `gorm:"size:512" json:"avatarUrl" example:"https://example.com/avatar.png"`
+	Locale      string `gorm:"size:16" json:"locale" example:"zh-CN"`
+	Timezone    string `gorm:"size:64" json:"timezone" example:"Asia/Shanghai"`
+	// EmailVerifiedAt 邮箱验证时间，修改邮箱后清空
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+
+	// TokenVersion 写入 token 的 ver 声明，自增后该用户此前签发的所有 token 失效
+	TokenVersion int `gorm:"default:0" json:"-"`
+
+	// MfaEnabled 是否已启用两步验证，TotpSecret 在确认绑定前同样会保存待确认的密钥
+	MfaEnabled bool   `gorm:"default:false" json:"mfaEnabled"`
+	TotpSecret string `gorm:"size:64" json:"-"`
+	// TotpLastStep 最近一次使用的验证码步长，防止同一验证码被重放
+	TotpLastStep int64 `gorm:"default:0" json:"-"`
+}
+
+// IsActive 只有启用状态的用户可以登录和访问接口
+func (u *User) IsActive() bool {
+	return u.Status == "" || u.Status == StatusActive
+}
+
+// HasPassword 通过第三方登录创建的用户没有本地密码
+func (u *User) HasPassword() bool {
+	return u.Password != ""
 }
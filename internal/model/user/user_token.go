@@ -0,0 +1,25 @@
+package user
+
+import (
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TokenPurposeEmailVerify   = "email_verify"
+	TokenPurposePasswordReset = "password_reset"
+)
+
+// UserToken 通过邮件下发的一次性 token，数据库只保存 SHA-256 摘要
+type UserToken struct {
+	base.BaseModel
+	UserId    uuid.UUID  `gorm:"type:char(36);index" json:"userId"`
+	Purpose   string     `gorm:"size:32;index" json:"purpose"`
+	TokenHash string     `gorm:"uniqueIndex;size:64" json:"-"`
+	Target    string     `gorm:"size:255" json:"target"` // 签发时的目标邮箱，邮箱变更后旧 token 失效
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyBytes     = 32
+	apiKeyPrefixLen = len(model.ApiKeyPrefix) + 8
+	// apiKeyTouchInterval 最近使用时间的更新间隔，避免每个请求都写库
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrApiKeyInvalid     = errors.New("api key invalid")
+	ErrApiKeyScope       = errors.New("api key scope not allowed")
+	ErrMfaSetupRequired  = errors.New("mfa setup required")
+	ErrApiKeyExpiresPast = errors.New("api key expiry in the past")
+)
+
+// CreatedApiKey 创建 API key 的返回结果，Token 明文只返回这一次
+type CreatedApiKey struct {
+	Token  string       `json:"token"`
+	ApiKey model.ApiKey `json:"apiKey"`
+}
+
+// IsApiKey 根据前缀判断凭证是否为 API key
+func IsApiKey(raw string) bool {
+	return strings.HasPrefix(raw, model.ApiKeyPrefix)
+}
+
+// CreateApiKey 为用户创建 API key，scopes 只能是用户当前拥有的权限
+func CreateApiKey(user *model.User, name string, scopes []string, expiresAt *time.Time) (CreatedApiKey, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return CreatedApiKey{}, ErrApiKeyExpiresPast
+	}
+
+	roles := roleNames(user.Roles)
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if _, ok := model.Permissions[scope]; !ok {
+			return CreatedApiKey{}, ErrApiKeyScope
+		}
+		allowed, err := HasPermission(roles, scope)
+		if err != nil {
+			return CreatedApiKey{}, err
+		}
+		if !allowed {
+			return CreatedApiKey{}, ErrApiKeyScope
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+
+	random, err := util.RandomToken(apiKeyBytes)
+	if err != nil {
+		return CreatedApiKey{}, err
+	}
+	rawKey := model.ApiKeyPrefix + random
+
+	key := model.ApiKey{
+		UserId:    user.Id,
+		Name:      name,
+		Prefix:    rawKey[:apiKeyPrefixLen],
+		KeyHash:   util.HashToken(rawKey),
+		Scopes:    normalized,
+		ExpiresAt: expiresAt,
+	}
+	if err := db.CreateApiKey(&key); err != nil {
+		return CreatedApiKey{}, err
+	}
+	return CreatedApiKey{Token: rawKey, ApiKey: key}, nil
+}
+
+// AuthenticateApiKey 校验 API key 并返回其所属用户对应的调用方，角色按用户当前角色实时加载
+func AuthenticateApiKey(rawKey string) (*Principal, error) {
+	key, err := db.GetApiKeyByHash(util.HashToken(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApiKeyInvalid
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsUsable(now) {
+		return nil, ErrApiKeyInvalid
+	}
+
+	owner, err := db.GetUserById(key.UserId.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApiKeyInvalid
+		}
+		return nil, err
+	}
+	if !owner.IsActive() {
+		return nil, ErrApiKeyInvalid
+	}
+	if MfaEnrollmentRequired(&owner) {
+		return nil, ErrMfaSetupRequired
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := db.TouchApiKey(key.Id, now); err != nil {
+			logger.Error("更新API key使用时间失败: %v", err)
+		}
+	}
+
+	return &Principal{
+		Kind:     PrincipalApiKey,
+		UserId:   owner.Id.String(),
+		Username: owner.Username,
+		Roles:    roleNames(owner.Roles),
+		Scopes:   key.Scopes,
+		ApiKeyId: key.Id,
+		Locale:   owner.Locale,
+	}, nil
+}
+
+// RevokeApiKey 吊销用户自己的 API key，立即生效
+func RevokeApiKey(userId uuid.UUID, id string) error {
+	revoked, err := db.RevokeUserApiKey(userId, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrApiKeyInvalid
+	}
+	return nil
+}
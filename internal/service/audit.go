@@ -0,0 +1,186 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	// auditBatchSize 单次写入的最大条数，队列中积压的事件合并为一次写入
+	auditBatchSize   = 100
+	maxAuditFieldLen = 512
+)
+
+// AuditEvent 一条待记录的审计事件，Outcome 为空时按成功记录
+type AuditEvent struct {
+	Action    string
+	Outcome   string
+	ActorId   string
+	ActorName string
+	// ImpersonatorId 模拟登录期间由 Audit 从当前调用方自动填入
+	ImpersonatorId   string
+	ImpersonatorName string
+	TargetType       string
+	TargetId         string
+	TargetName       string
+	Detail           string
+}
+
+// UserTarget 以用户作为操作对象
+func (e AuditEvent) UserTarget(user *model.User) AuditEvent {
+	e.TargetType = "user"
+	e.TargetId = user.Id.String()
+	e.TargetName = user.Username
+	return e
+}
+
+// Failed 标记为失败事件并记录原因
+func (e AuditEvent) Failed(detail string) AuditEvent {
+	e.Outcome = model.AuditFailure
+	e.Detail = detail
+	return e
+}
+
+var (
+	auditQueue   chan model.AuditLog
+	auditOnce    sync.Once
+	auditPending sync.WaitGroup
+)
+
+// Audit 记录当前请求中发生的审计事件，未指定 actor 时取当前调用方，IP 与 User-Agent 取自请求；
+// 模拟登录期间 actor 为被模拟的用户，同时记录实际操作的管理员；机器客户端以 client_id 作为 actor
+func Audit(c fiber.Ctx, event AuditEvent) {
+	if principal := PrincipalFrom(c); principal != nil {
+		if event.ActorId == "" {
+			event.ActorId = principal.UserId
+			if principal.IsClient() {
+				event.ActorId = principal.ClientId
+			}
+			if event.ActorName == "" {
+				event.ActorName = principal.Username
+			}
+		}
+		if principal.IsImpersonated() {
+			event.ImpersonatorId = principal.ImpersonatorId
+			event.ImpersonatorName = principal.ImpersonatorName
+		}
+	}
+	RecordAudit(ClientInfoFrom(c), event)
+}
+
+// AuditAs 以指定用户作为 actor 记录事件，用于登录等尚未经过 Auth 中间件的请求
+func AuditAs(c fiber.Ctx, actor *model.User, event AuditEvent) {
+	auditUser(ClientInfoFrom(c), actor, event)
+}
+
+func auditUser(client ClientInfo, actor *model.User, event AuditEvent) {
+	event.ActorId = actor.Id.String()
+	event.ActorName = actor.Username
+	RecordAudit(client, event)
+}
+
+// RecordAudit 将审计事件放入写入队列后立即返回，不阻塞请求处理；队列已满时丢弃并记录错误日志
+func RecordAudit(client ClientInfo, event AuditEvent) {
+	auditOnce.Do(startAuditWorker)
+
+	outcome := event.Outcome
+	if outcome == "" {
+		outcome = model.AuditSuccess
+	}
+	entry := model.AuditLog{
+		OccurredAt:       time.Now(),
+		Action:           event.Action,
+		Outcome:          outcome,
+		ActorId:          event.ActorId,
+		ActorName:        truncateAuditField(event.ActorName, 128),
+		ImpersonatorId:   event.ImpersonatorId,
+		ImpersonatorName: truncateAuditField(event.ImpersonatorName, 128),
+		TargetType:       event.TargetType,
+		TargetId:         event.TargetId,
+		TargetName:       truncateAuditField(event.TargetName, 128),
+		Ip:               client.Ip,
+		UserAgent:        truncateAuditField(client.UserAgent, maxAuditFieldLen),
+		Detail:           truncateAuditField(event.Detail, maxAuditFieldLen),
+	}
+
+	auditPending.Add(1)
+	select {
+	case auditQueue <- entry:
+	default:
+		auditPending.Done()
+		logger.Error("审计日志队列已满，丢弃事件: action=%s outcome=%s actor=%s target=%s ip=%s",
+			entry.Action, entry.Outcome, entry.ActorId, entry.TargetId, entry.Ip)
+	}
+}
+
+// WaitAudit 阻塞直到队列中的审计事件全部写入，用于测试和优雅退出
+func WaitAudit() {
+	auditPending.Wait()
+}
+
+func startAuditWorker() {
+	auditQueue = make(chan model.AuditLog, config.Current.Audit.QueueLength())
+
+	go func() {
+		batch := make([]model.AuditLog, 0, auditBatchSize)
+		for entry := range auditQueue {
+			batch = append(batch[:0], entry)
+		drain:
+			for len(batch) < auditBatchSize {
+				select {
+				case next := <-auditQueue:
+					batch = append(batch, next)
+				default:
+					break drain
+				}
+			}
+
+			writeAuditLogs(batch)
+			auditPending.Add(-len(batch))
+		}
+	}()
+}
+
+func writeAuditLogs(logs []model.AuditLog) {
+	if db.DB == nil {
+		logger.Warn("数据库未初始化，丢弃%d条审计日志", len(logs))
+		return
+	}
+	if err := db.CreateAuditLogs(logs); err != nil {
+		logger.Error("写入审计日志失败: count=%d err=%v", len(logs), err)
+	}
+}
+
+// StartAuditCleanup 定期清理超过 audit.retentionDays 的审计日志，未配置保留期时不清理
+func StartAuditCleanup(interval time.Duration) {
+	retention := config.Current.Audit.Retention()
+	if retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := db.DeleteAuditLogsBefore(time.Now().Add(-retention)); err != nil {
+				logger.Error("清理审计日志失败: %v", err)
+			}
+		}
+	}()
+}
+
+func truncateAuditField(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	return strings.ToValidUTF8(value[:limit], "")
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/jwtkey"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// TokenTypeClient 机器客户端通过 client credentials 获取的 access token
+	TokenTypeClient = "client"
+
+	clientIdBytes     = 12
+	clientSecretBytes = 32
+)
+
+var (
+	ErrClientInvalid = errors.New("client invalid")
+	ErrClientScope   = errors.New("client scope not allowed")
+)
+
+// CreatedClient 创建机器客户端的返回结果，ClientSecret 明文只返回这一次
+type CreatedClient struct {
+	ClientSecret string            `json:"clientSecret"`
+	Client       model.OAuthClient `json:"client"`
+}
+
+// ClientToken client credentials 授权的响应，字段按 RFC 6749 命名
+type ClientToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// CreateClient 注册机器客户端，scopes 只能是创建者当前拥有的权限
+func CreateClient(creator *model.User, name string, scopes []string) (CreatedClient, error) {
+	roles := roleNames(creator.Roles)
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if _, ok := model.Permissions[scope]; !ok {
+			return CreatedClient{}, ErrClientScope
+		}
+		allowed, err := HasPermission(roles, scope)
+		if err != nil {
+			return CreatedClient{}, err
+		}
+		if !allowed {
+			return CreatedClient{}, ErrClientScope
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+
+	id, err := util.RandomToken(clientIdBytes)
+	if err != nil {
+		return CreatedClient{}, err
+	}
+	secret, err := util.RandomToken(clientSecretBytes)
+	if err != nil {
+		return CreatedClient{}, err
+	}
+	rawSecret := model.ClientSecretPrefix + secret
+
+	client := model.OAuthClient{
+		ClientId:   model.ClientIdPrefix + id,
+		Name:       name,
+		SecretHash: util.HashToken(rawSecret),
+		Scopes:     normalized,
+	}
+	if err := db.CreateOAuthClient(&client); err != nil {
+		return CreatedClient{}, err
+	}
+	return CreatedClient{ClientSecret: rawSecret, Client: client}, nil
+}
+
+// AuthenticateClient 校验 client_id 与 client_secret
+func AuthenticateClient(clientId string, secret string) (*model.OAuthClient, error) {
+	if clientId == "" || secret == "" {
+		return nil, ErrClientInvalid
+	}
+	client, err := db.GetOAuthClientByClientId(clientId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientInvalid
+		}
+		return nil, err
+	}
+	if !client.IsUsable() || subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(util.HashToken(secret))) != 1 {
+		return nil, ErrClientInvalid
+	}
+	return &client, nil
+}
+
+// IssueClientToken 为机器客户端签发 access token，scopes 为空时授予客户端允许的全部权限。
+// 签名方式与用户 token 相同，sub 与 client_id 声明为客户端的 client_id，不签发 refresh token。
+func IssueClientToken(client *model.OAuthClient, scopes []string) (ClientToken, error) {
+	granted := client.Scopes
+	if len(scopes) > 0 {
+		granted = make([]string, 0, len(scopes))
+		for _, scope := range scopes {
+			if !slices.Contains(client.Scopes, scope) {
+				return ClientToken{}, ErrClientScope
+			}
+			if !slices.Contains(granted, scope) {
+				granted = append(granted, scope)
+			}
+		}
+	}
+
+	now := time.Now()
+	ttl := config.Current.Jwt.ClientTTL()
+	scope := strings.Join(granted, " ")
+	claims := jwt.MapClaims{
+		"jti":       uuid.NewString(),
+		"typ":       TokenTypeClient,
+		"sub":       client.ClientId,
+		"client_id": client.ClientId,
+		"scope":     scope,
+		"iat":       now.Unix(),
+		"exp":       now.Add(ttl).Unix(),
+	}
+	token, err := jwtkey.Active().Sign(claims)
+	if err != nil {
+		return ClientToken{}, err
+	}
+	return ClientToken{AccessToken: token, TokenType: "Bearer", ExpiresIn: int64(ttl.Seconds()), Scope: scope}, nil
+}
+
+// AuthenticateClientToken 校验机器客户端 token 并返回对应的调用方；客户端被吊销后 token 立即失效，
+// 可用的权限为 token 中的 scope 与客户端当前允许的 scope 的交集
+func AuthenticateClientToken(token *jwt.Token) (*Principal, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, ErrClientInvalid
+	}
+	clientId, _ := claims["client_id"].(string)
+	if clientId == "" {
+		return nil, ErrClientInvalid
+	}
+
+	client, err := db.GetOAuthClientByClientId(clientId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientInvalid
+		}
+		return nil, err
+	}
+	if !client.IsUsable() {
+		return nil, ErrClientInvalid
+	}
+
+	now := time.Now()
+	if client.LastUsedAt == nil || now.Sub(*client.LastUsedAt) > apiKeyTouchInterval {
+		if err := db.TouchOAuthClient(client.Id, now); err != nil {
+			logger.Error("更新机器客户端使用时间失败: %v", err)
+		}
+	}
+
+	scope, _ := claims["scope"].(string)
+	scopes := make([]string, 0, len(client.Scopes))
+	for _, granted := range strings.Fields(scope) {
+		if slices.Contains(client.Scopes, granted) {
+			scopes = append(scopes, granted)
+		}
+	}
+	return &Principal{
+		Kind:     PrincipalClient,
+		Username: client.Name,
+		Scopes:   scopes,
+		ClientId: client.ClientId,
+	}, nil
+}
+
+// RevokeClient 吊销机器客户端，已签发的 token 立即失效
+func RevokeClient(id string) (*model.OAuthClient, error) {
+	client, err := db.GetOAuthClientById(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrClientInvalid
+		}
+		return nil, err
+	}
+	revoked, err := db.RevokeOAuthClient(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		return nil, ErrClientInvalid
+	}
+	return &client, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/extractors"
+)
+
+const (
+	csrfTokenBytes = 32
+	// refreshCookiePath refresh token cookie 只发送到刷新和退出登录所在的路径
+	refreshCookiePath = "/api/auth"
+)
+
+// CookieMode 是否启用 cookie 会话模式
+func CookieMode() bool {
+	return config.Current.Cookie.Enabled
+}
+
+// SetAuthCookies 将 token 写入 HttpOnly cookie，并生成新的 CSRF token 写入前端可读的 cookie
+func SetAuthCookies(c fiber.Ctx, pair TokenPair) error {
+	csrfToken, err := util.RandomToken(csrfTokenBytes)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.Current.Cookie
+	c.Cookie(authCookie(cfg.AccessCookie(), pair.Token, "/", int(pair.ExpiresIn), true))
+	c.Cookie(authCookie(cfg.RefreshCookie(), pair.RefreshToken, refreshCookiePath, int(pair.RefreshExpiresIn), true))
+	c.Cookie(authCookie(cfg.CsrfCookie(), csrfToken, "/", int(pair.RefreshExpiresIn), false))
+	return nil
+}
+
+// ClearAuthCookies 退出登录时删除全部认证 cookie
+func ClearAuthCookies(c fiber.Ctx) {
+	cfg := config.Current.Cookie
+	for _, cookie := range []*fiber.Cookie{
+		authCookie(cfg.AccessCookie(), "", "/", -1, true),
+		authCookie(cfg.RefreshCookie(), "", refreshCookiePath, -1, true),
+		authCookie(cfg.CsrfCookie(), "", "/", -1, false),
+	} {
+		cookie.Expires = time.Unix(0, 0)
+		c.Cookie(cookie)
+	}
+}
+
+// bearerExtractor 与认证中间件使用相同的规则读取 Authorization: Bearer 头
+var bearerExtractor = extractors.FromAuthHeader("Bearer")
+
+// AccessTokenFromCookie 请求通过 cookie 中的 access token 认证：只要 Authorization 头中没有可用的 Bearer token，
+// 认证中间件就会回退到 cookie，其他认证方案（如 Basic）的 Authorization 头不能免除 CSRF 校验
+func AccessTokenFromCookie(c fiber.Ctx) bool {
+	if !CookieMode() || c.Cookies(config.Current.Cookie.AccessCookie()) == "" {
+		return false
+	}
+	_, err := bearerExtractor.Extract(c)
+	return err != nil
+}
+
+// RefreshTokenFromCookie 读取 cookie 中的 refresh token，未启用 cookie 会话模式时返回空字符串
+func RefreshTokenFromCookie(c fiber.Ctx) string {
+	if !CookieMode() {
+		return ""
+	}
+	return c.Cookies(config.Current.Cookie.RefreshCookie())
+}
+
+// ValidCsrf double-submit 校验：请求头中的 CSRF token 必须与 CSRF cookie 一致，GET 等安全方法不校验
+func ValidCsrf(c fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+
+	cfg := config.Current.Cookie
+	cookie := c.Cookies(cfg.CsrfCookie())
+	header := c.Get(cfg.CsrfHeaderName())
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+func authCookie(name string, value string, path string, maxAge int, httpOnly bool) *fiber.Cookie {
+	cfg := config.Current.Cookie
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   !cfg.Insecure,
+		HTTPOnly: httpOnly,
+		SameSite: cfg.SameSiteMode(),
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/i18n"
+	"go-fiber-starter/pkg/mailer"
+	"go-fiber-starter/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerifyTTL   = 24 * time.Hour
+	passwordResetTTL = time.Hour
+	userTokenBytes   = 32
+)
+
+var ErrUserTokenInvalid = errors.New("user token invalid or expired")
+
+// SendEmailVerification 向用户当前邮箱异步发送验证邮件
+func SendEmailVerification(user *model.User) error {
+	if user.Email == "" {
+		return nil
+	}
+
+	rawToken, err := issueUserToken(user, model.TokenPurposeEmailVerify, emailVerifyTTL)
+	if err != nil {
+		return err
+	}
+	msg, err := mailer.Render("verify_email", user.Email, mailData(user, "verify_email", "/verify-email", rawToken, emailVerifyTTL))
+	if err != nil {
+		return err
+	}
+
+	mailer.SendAsync(msg)
+	return nil
+}
+
+// VerifyEmail 使用邮件中的 token 完成邮箱验证
+func VerifyEmail(rawToken string) error {
+	token, user, err := consumeUserToken(model.TokenPurposeEmailVerify, rawToken)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(user.Email, token.Target) {
+		return ErrUserTokenInvalid
+	}
+
+	return db.UpdateUserFields(&user, map[string]interface{}{"email_verified_at": time.Now()})
+}
+
+// RequestPasswordReset 发送重置密码邮件，邮箱不存在时同样返回成功，避免泄露账号是否存在
+func RequestPasswordReset(email string) error {
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive() {
+		return nil
+	}
+
+	rawToken, err := issueUserToken(&user, model.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+	msg, err := mailer.Render("reset_password", user.Email, mailData(&user, "reset_password", "/reset-password", rawToken, passwordResetTTL))
+	if err != nil {
+		return err
+	}
+
+	mailer.SendAsync(msg)
+	return nil
+}
+
+// ResetPassword 使用邮件中的 token 设置新密码，并吊销用户全部会话
+func ResetPassword(rawToken string, passwordHash string) error {
+	token, user, err := consumeUserToken(model.TokenPurposePasswordReset, rawToken)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(user.Email, token.Target) {
+		return ErrUserTokenInvalid
+	}
+
+	if err := db.UpdateUserFields(&user, map[string]interface{}{"password": passwordHash}); err != nil {
+		return err
+	}
+	return RevokeAllUserTokens(user.Id)
+}
+
+// PasswordResetUser 返回重置链接对应的用户但不消耗 token，用于在重置前按用户名校验密码策略
+func PasswordResetUser(rawToken string) (model.User, error) {
+	token, err := db.GetUserTokenByHash(model.TokenPurposePasswordReset, util.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.User{}, ErrUserTokenInvalid
+		}
+		return model.User{}, err
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return model.User{}, ErrUserTokenInvalid
+	}
+
+	user, err := db.GetUserById(token.UserId.String())
+	if err != nil {
+		return model.User{}, ErrUserTokenInvalid
+	}
+	return user, nil
+}
+
+func issueUserToken(user *model.User, purpose string, ttl time.Duration) (string, error) {
+	rawToken, err := util.RandomToken(userTokenBytes)
+	if err != nil {
+		return "", err
+	}
+
+	if err := db.CreateUserToken(&model.UserToken{
+		UserId:    user.Id,
+		Purpose:   purpose,
+		TokenHash: util.HashToken(rawToken),
+		Target:    user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// consumeUserToken 校验并作废一次性 token，返回 token 和所属用户
+func consumeUserToken(purpose string, rawToken string) (model.UserToken, model.User, error) {
+	token, err := db.GetUserTokenByHash(purpose, util.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return token, model.User{}, ErrUserTokenInvalid
+		}
+		return token, model.User{}, err
+	}
+
+	now := time.Now()
+	if token.UsedAt != nil || now.After(token.ExpiresAt) {
+		return token, model.User{}, ErrUserTokenInvalid
+	}
+	consumed, err := db.ConsumeUserToken(token.Id, now)
+	if err != nil {
+		return token, model.User{}, err
+	}
+	if !consumed {
+		return token, model.User{}, ErrUserTokenInvalid
+	}
+
+	user, err := db.GetUserById(token.UserId.String())
+	if err != nil {
+		return token, model.User{}, ErrUserTokenInvalid
+	}
+	return token, user, nil
+}
+
+// mailData 按收件人的语言偏好生成邮件文案，文案在语言包的 mail.<template>.* 下，未设置或不支持时使用默认语言
+func mailData(user *model.User, template string, path string, rawToken string, ttl time.Duration) map[string]string {
+	name := user.DisplayName
+	if name == "" {
+		name = user.Username
+	}
+	locale, ok := i18n.Current.Match(user.Locale)
+	if !ok {
+		locale = i18n.Current.Default()
+	}
+	expiresIn := i18n.T(locale, "mail.hours", i18n.Params{"count": int(ttl.Hours())})
+
+	link := strings.TrimRight(config.Current.Mail.LinkBaseUrl, "/") + path + "?token=" + url.QueryEscape(rawToken)
+	return map[string]string{
+		"Subject":  i18n.T(locale, "mail."+template+".subject"),
+		"Greeting": i18n.T(locale, "mail.greeting", i18n.Params{"name": name}),
+		"Intro":    i18n.T(locale, "mail."+template+".intro", i18n.Params{"expiresIn": expiresIn}),
+		"Footer":   i18n.T(locale, "mail."+template+".footer"),
+		"Link":     link,
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const inviteCodeBytes = 18
+
+var (
+	ErrInviteInvalid     = errors.New("invite invalid or expired")
+	ErrInviteRole        = errors.New("invite role not found")
+	ErrInviteExpiresPast = errors.New("invite expiry in the past")
+)
+
+// CreatedInvite 创建邀请码的返回结果，Code 明文只返回这一次
+type CreatedInvite struct {
+	Code   string       `json:"code"`
+	Invite model.Invite `json:"invite"`
+}
+
+// CreateInvite 创建一次性邀请码，role 为空时注册后只分配默认角色
+func CreateInvite(createdBy uuid.UUID, role string, note string, expiresAt *time.Time) (CreatedInvite, error) {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return CreatedInvite{}, ErrInviteExpiresPast
+	}
+	role = strings.TrimSpace(role)
+	if role != "" {
+		if _, err := db.GetRoleByName(role); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return CreatedInvite{}, ErrInviteRole
+			}
+			return CreatedInvite{}, err
+		}
+	}
+
+	code, err := util.RandomToken(inviteCodeBytes)
+	if err != nil {
+		return CreatedInvite{}, err
+	}
+	invite := model.Invite{
+		CodeHash:  util.HashToken(code),
+		Role:      role,
+		Note:      strings.TrimSpace(note),
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}
+	if err := db.CreateInvite(&invite); err != nil {
+		return CreatedInvite{}, err
+	}
+	return CreatedInvite{Code: code, Invite: invite}, nil
+}
+
+// FindInvite 按明文查找可用的邀请码
+func FindInvite(code string) (model.Invite, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return model.Invite{}, ErrInviteInvalid
+	}
+
+	invite, err := db.GetInviteByHash(util.HashToken(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Invite{}, ErrInviteInvalid
+		}
+		return model.Invite{}, err
+	}
+	if !invite.IsUsable(time.Now()) {
+		return model.Invite{}, ErrInviteInvalid
+	}
+	return invite, nil
+}
+
+// CreateInvitedUser 创建用户并核销邀请码，邀请码已被使用时返回 ErrInviteInvalid 且不会创建用户
+func CreateInvitedUser(user *model.User, invite *model.Invite) error {
+	created, err := db.CreateUserWithInvite(user, invite.Id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrInviteInvalid
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/throttle"
+)
+
+type loginLimiters struct {
+	account *throttle.Limiter
+	ip      *throttle.Limiter
+}
+
+var (
+	loginGuardMu sync.RWMutex
+	// loginGuard 未启用登录保护时为 nil
+	loginGuard *loginLimiters
+)
+
+// InitLoginProtection 按 security.login 配置创建登录失败计数器，未启用时不做任何限制
+func InitLoginProtection() {
+	loginConfig := config.Current.Security.Login
+
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+
+	if !loginConfig.Enabled {
+		loginGuard = nil
+		return
+	}
+
+	var store throttle.Store
+	if loginConfig.Store == "db" {
+		store = db.ThrottleStore{}
+	} else {
+		store = throttle.NewMemoryStore(loginConfig.WindowDuration())
+	}
+
+	policy := throttle.Policy{
+		Window:      loginConfig.WindowDuration(),
+		BaseLockout: loginConfig.BaseLockoutDuration(),
+		MaxLockout:  loginConfig.MaxLockoutDuration(),
+	}
+	accountPolicy, ipPolicy := policy, policy
+	accountPolicy.Threshold = loginConfig.AccountLimit()
+	ipPolicy.Threshold = loginConfig.IpLimit()
+
+	loginGuard = &loginLimiters{
+		account: throttle.NewLimiter(store, accountPolicy),
+		ip:      throttle.NewLimiter(store, ipPolicy),
+	}
+}
+
+// StartLoginProtectionCleanup 定期清理数据库中过期的登录失败记录，仅 store=db 时需要
+func StartLoginProtectionCleanup(interval time.Duration) {
+	loginConfig := config.Current.Security.Login
+	if !loginConfig.Enabled || loginConfig.Store != "db" {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := db.DeleteStaleLoginAttempts(time.Now().Add(-loginConfig.WindowDuration())); err != nil {
+				logger.Error("清理登录失败记录失败: %v", err)
+			}
+		}
+	}()
+}
+
+// LoginLockedError 账号或 IP 处于登录锁定期，RetryAfter 为剩余的锁定时间
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("login locked, retry after %s", e.RetryAfter)
+}
+
+// LoginRetryAfter 返回账号或 IP 剩余的锁定时间，未锁定时为 0
+func LoginRetryAfter(username string, ip string) (time.Duration, error) {
+	guard := currentLoginGuard()
+	if guard == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	accountWait, err := guard.account.RetryAfter(accountKey(username), now)
+	if err != nil {
+		return 0, err
+	}
+	ipWait, err := guard.ip.RetryAfter(ipKey(ip), now)
+	if err != nil {
+		return 0, err
+	}
+	return max(accountWait, ipWait), nil
+}
+
+// RecordLoginFailure 记录一次登录失败，返回本次失败触发的锁定时间；触发锁定时写入 auth.login_lockout 审计事件
+func RecordLoginFailure(username string, client ClientInfo) (time.Duration, error) {
+	guard := currentLoginGuard()
+	if guard == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var lockout time.Duration
+	for _, item := range []struct {
+		limiter *throttle.Limiter
+		key     string
+	}{
+		{guard.account, accountKey(username)},
+		{guard.ip, ipKey(client.Ip)},
+	} {
+		entry, err := item.limiter.Fail(item.key, now)
+		if err != nil {
+			return 0, err
+		}
+		if now.Before(entry.LockedUntil) {
+			logger.Warn("登录失败次数过多，临时锁定: key=%s failures=%d lockedUntil=%s ip=%s",
+				item.key, entry.Failures, entry.LockedUntil.Format(time.RFC3339), client.Ip)
+			RecordAudit(client, AuditEvent{
+				Action:     model.AuditLoginLockout,
+				ActorName:  username,
+				TargetType: "login_attempt",
+				TargetName: item.key,
+			}.Failed(fmt.Sprintf("key=%s failures=%d lockedUntil=%s",
+				item.key, entry.Failures, entry.LockedUntil.UTC().Format(time.RFC3339))))
+			lockout = max(lockout, entry.LockedUntil.Sub(now))
+		}
+	}
+	return lockout, nil
+}
+
+// ResetLoginFailures 登录成功后清除账号的失败计数，IP 计数保留到窗口期结束；启用两步验证的用户在验证码通过后才清除
+func ResetLoginFailures(username string) error {
+	guard := currentLoginGuard()
+	if guard == nil {
+		return nil
+	}
+	return guard.account.Reset(accountKey(username))
+}
+
+func currentLoginGuard() *loginLimiters {
+	loginGuardMu.RLock()
+	defer loginGuardMu.RUnlock()
+
+	return loginGuard
+}
+
+func accountKey(username string) string {
+	return db.LoginAttemptAccountKey(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
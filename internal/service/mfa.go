@@ -0,0 +1,357 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/jwtkey"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/totp"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	TokenTypeAccess     = "access"
+	TokenTypeMfaPending = "mfa_pending"
+
+	recoveryCodeCount = 10
+	recoveryCodeLen   = 10
+	// totpSkew 允许前后各一个步长的时钟误差
+	totpSkew = 1
+	// maxMfaAttempts 同一个 mfa pending token 允许的最大失败次数，超过后需要重新登录；
+	// 失败同时计入账号和 IP 的登录失败次数，重新登录换取新 token 也无法绕过登录锁定
+	maxMfaAttempts = 5
+)
+
+var (
+	ErrMfaCodeInvalid       = errors.New("mfa code invalid")
+	ErrMfaPendingInvalid    = errors.New("mfa pending token invalid")
+	ErrMfaAlreadyEnabled    = errors.New("mfa already enabled")
+	ErrMfaNotEnrolled       = errors.New("mfa not enrolled")
+	ErrMfaRequiredByRole    = errors.New("mfa required by role")
+	ErrMfaTooManyAttempts   = errors.New("too many mfa attempts")
+	recoveryCodeEncoding    = base32.StdEncoding.WithPadding(base32.NoPadding)
+	recoveryCodeReplacement = strings.NewReplacer("-", "", " ", "")
+)
+
+// MfaChallenge 已启用两步验证的用户登录第一步的返回结果
+type MfaChallenge struct {
+	MfaRequired bool   `json:"mfaRequired"`
+	MfaToken    string `json:"mfaToken"`
+	ExpiresIn   int64  `json:"expiresIn"`
+}
+
+// MfaEnrollment 发起绑定时返回的密钥和验证器扫码地址
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+}
+
+type mfaAttemptEntry struct {
+	failures  int
+	expiresAt time.Time
+}
+
+// mfaAttempts 记录 mfa pending token 的失败次数，防止在有效期内穷举验证码
+var mfaAttempts = struct {
+	mu      sync.Mutex
+	entries map[string]mfaAttemptEntry
+}{entries: make(map[string]mfaAttemptEntry)}
+
+// MfaEnrollmentRequired 用户所属角色要求两步验证但尚未启用
+func MfaEnrollmentRequired(user *model.User) bool {
+	if user.MfaEnabled {
+		return false
+	}
+	for _, role := range user.Roles {
+		if role.RequireMfa {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueMfaChallenge 签发只能在 /api/auth/mfa/verify 使用的短期 token
+func IssueMfaChallenge(user *model.User) (MfaChallenge, error) {
+	now := time.Now()
+	ttl := config.Current.Mfa.PendingTTL()
+	claims := jwt.MapClaims{
+		"jti":     uuid.NewString(),
+		"typ":     TokenTypeMfaPending,
+		"user_id": user.Id,
+		"ver":     user.TokenVersion,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
+	}
+	token, err := jwtkey.Active().Sign(claims)
+	if err != nil {
+		return MfaChallenge{}, err
+	}
+
+	return MfaChallenge{MfaRequired: true, MfaToken: token, ExpiresIn: int64(ttl.Seconds())}, nil
+}
+
+// CompleteMfaLogin 使用 mfa pending token 和验证码（或恢复码）完成登录，pending token 使用后立即失效
+func CompleteMfaLogin(rawToken string, code string, client ClientInfo) (TokenPair, error) {
+	token, err := jwt.Parse(rawToken, jwtkey.Active().Keyfunc)
+	if err != nil || !token.Valid || TokenType(token) != TokenTypeMfaPending {
+		return TokenPair{}, ErrMfaPendingInvalid
+	}
+	if err := CheckTokenRevoked(token); err != nil {
+		return TokenPair{}, ErrMfaPendingInvalid
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	userId, err := parseUserIDClaim(claims)
+	if err != nil {
+		return TokenPair{}, ErrMfaPendingInvalid
+	}
+	user, err := db.GetUserById(userId)
+	if err != nil || !user.IsActive() || !user.MfaEnabled {
+		return TokenPair{}, ErrMfaPendingInvalid
+	}
+
+	retryAfter, err := LoginRetryAfter(user.Username, client.Ip)
+	if err != nil {
+		logger.Error("读取登录失败记录失败: %v", err)
+	}
+	if retryAfter > 0 {
+		auditUser(client, &user, AuditEvent{Action: model.AuditMfaVerify}.Failed("locked"))
+		return TokenPair{}, &LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	jti, _ := claims["jti"].(string)
+	if err := VerifyMfaCode(&user, code, true); err != nil {
+		if errors.Is(err, ErrMfaCodeInvalid) {
+			lockout, err := RecordLoginFailure(user.Username, client)
+			if err != nil {
+				logger.Error("记录登录失败失败: %v", err)
+			}
+			tooMany := recordMfaFailure(jti, token)
+			detail := "invalid_code"
+			if lockout > 0 {
+				detail = "invalid_code, locked"
+			} else if tooMany {
+				detail = "too_many_attempts"
+			}
+			auditUser(client, &user, AuditEvent{Action: model.AuditMfaVerify}.Failed(detail))
+			if tooMany || lockout > 0 {
+				if err := RevokeToken(token); err != nil {
+					return TokenPair{}, err
+				}
+			}
+			if lockout > 0 {
+				return TokenPair{}, &LoginLockedError{RetryAfter: lockout}
+			}
+			if tooMany {
+				return TokenPair{}, ErrMfaTooManyAttempts
+			}
+		}
+		return TokenPair{}, err
+	}
+
+	clearMfaFailures(jti)
+	if err := ResetLoginFailures(user.Username); err != nil {
+		logger.Error("清除登录失败记录失败: %v", err)
+	}
+	if err := RevokeToken(token); err != nil {
+		return TokenPair{}, err
+	}
+	pair, err := IssueTokenPair(&user, client)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	auditUser(client, &user, AuditEvent{Action: model.AuditMfaVerify})
+	return pair, nil
+}
+
+// VerifyMfaCode 校验 TOTP 验证码，allowRecovery 为 true 时也接受恢复码；验证码和恢复码都只能使用一次
+func VerifyMfaCode(user *model.User, code string, allowRecovery bool) error {
+	code = strings.TrimSpace(code)
+	if code == "" || user.TotpSecret == "" {
+		return ErrMfaCodeInvalid
+	}
+
+	if step, ok := totp.Validate(user.TotpSecret, code, time.Now(), totpSkew); ok {
+		used, err := db.UseTotpStep(user.Id, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrMfaCodeInvalid
+		}
+		user.TotpLastStep = step
+		return nil
+	}
+
+	if !allowRecovery {
+		return ErrMfaCodeInvalid
+	}
+	used, err := db.UseRecoveryCode(user.Id, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrMfaCodeInvalid
+	}
+	return nil
+}
+
+// StartMfaEnrollment 生成新的 TOTP 密钥，确认前不会启用两步验证
+func StartMfaEnrollment(user *model.User) (MfaEnrollment, error) {
+	if user.MfaEnabled {
+		return MfaEnrollment{}, ErrMfaAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return MfaEnrollment{}, err
+	}
+	if err := db.UpdateUserFields(user, map[string]interface{}{"totp_secret": secret, "totp_last_step": 0}); err != nil {
+		return MfaEnrollment{}, err
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Username
+	}
+	issuer := config.Current.Mfa.IssuerName()
+	return MfaEnrollment{Secret: secret, Uri: totp.ProvisioningURI(issuer, account, secret)}, nil
+}
+
+// ConfirmMfaEnrollment 校验验证器生成的验证码后启用两步验证，返回一次性展示的恢复码
+func ConfirmMfaEnrollment(user *model.User, code string) ([]string, error) {
+	if user.MfaEnabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	if user.TotpSecret == "" {
+		return nil, ErrMfaNotEnrolled
+	}
+	if err := VerifyMfaCode(user, code, false); err != nil {
+		return nil, err
+	}
+
+	if err := db.UpdateUserFields(user, map[string]interface{}{"mfa_enabled": true}); err != nil {
+		return nil, err
+	}
+	return RegenerateRecoveryCodes(user)
+}
+
+// DisableMfa 关闭两步验证并删除密钥与恢复码，角色要求两步验证时不允许关闭
+func DisableMfa(user *model.User) error {
+	for _, role := range user.Roles {
+		if role.RequireMfa {
+			return ErrMfaRequiredByRole
+		}
+	}
+
+	if err := db.UpdateUserFields(user, map[string]interface{}{
+		"mfa_enabled":    false,
+		"totp_secret":    "",
+		"totp_last_step": 0,
+	}); err != nil {
+		return err
+	}
+	return db.ReplaceRecoveryCodes(user.Id, nil)
+}
+
+// RegenerateRecoveryCodes 生成一组新的恢复码，旧恢复码全部作废
+func RegenerateRecoveryCodes(user *model.User) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, model.RecoveryCode{UserId: user.Id, CodeHash: hashRecoveryCode(code)})
+	}
+
+	if err := db.ReplaceRecoveryCodes(user.Id, records); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// TokenType 读取 token 的 typ 声明，旧版本签发的 token 没有该声明，视为 access token
+func TokenType(token *jwt.Token) string {
+	if token == nil {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	typ, _ := claims["typ"].(string)
+	if typ == "" {
+		return TokenTypeAccess
+	}
+	return typ
+}
+
+// TokenRequiresMfaSetup token 签发时用户所属角色要求两步验证但尚未启用
+func TokenRequiresMfaSetup(token *jwt.Token) bool {
+	if token == nil {
+		return false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	required, _ := claims["mfa_setup"].(bool)
+	return required
+}
+
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(buf))[:recoveryCodeLen]
+	return code[:recoveryCodeLen/2] + "-" + code[recoveryCodeLen/2:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	return util.HashToken(strings.ToLower(recoveryCodeReplacement.Replace(code)))
+}
+
+// recordMfaFailure 记录一次验证失败，返回 true 表示已达到失败上限
+func recordMfaFailure(jti string, token *jwt.Token) bool {
+	now := time.Now()
+	expiresAt := now.Add(config.Current.Mfa.PendingTTL())
+	if exp, err := token.Claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+
+	mfaAttempts.mu.Lock()
+	defer mfaAttempts.mu.Unlock()
+
+	for key, entry := range mfaAttempts.entries {
+		if now.After(entry.expiresAt) {
+			delete(mfaAttempts.entries, key)
+		}
+	}
+	entry := mfaAttempts.entries[jti]
+	entry.failures++
+	entry.expiresAt = expiresAt
+	mfaAttempts.entries[jti] = entry
+	return entry.failures >= maxMfaAttempts
+}
+
+func clearMfaFailures(jti string) {
+	mfaAttempts.mu.Lock()
+	defer mfaAttempts.mu.Unlock()
+
+	delete(mfaAttempts.entries, jti)
+}
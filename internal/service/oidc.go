@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/jwtkey"
+	"go-fiber-starter/pkg/oidc"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	TokenTypeOidcState = "oidc_state"
+
+	// OidcStateTTL 从跳转身份提供方到回调的最长时间
+	OidcStateTTL     = 10 * time.Minute
+	oidcStateBytes   = 24
+	maxUsernameLen   = 48
+	usernameAttempts = 5
+)
+
+var (
+	ErrOidcStateInvalid   = errors.New("oidc state invalid")
+	ErrOidcSignupDisabled = errors.New("oidc signup disabled")
+
+	usernameDisallowed = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// StartOidcLogin 生成身份提供方授权地址，以及需要写入 cookie 的签名登录状态（包含 state、nonce 和 PKCE verifier）
+func StartOidcLogin(ctx context.Context, providerName string) (authURL string, stateCookie string, err error) {
+	provider, err := oidc.Get(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := util.RandomToken(oidcStateBytes)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := util.RandomToken(oidcStateBytes)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	stateCookie, err = jwtkey.Active().Sign(jwt.MapClaims{
+		"typ":      TokenTypeOidcState,
+		"provider": providerName,
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+		"iat":      now.Unix(),
+		"exp":      now.Add(OidcStateTTL).Unix(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, stateCookie, nil
+}
+
+// CompleteOidcLogin 校验回调的 state，换取并校验 ID Token，返回关联或新建的本地用户
+func CompleteOidcLogin(ctx context.Context, providerName string, code string, state string, stateCookie string) (model.User, error) {
+	provider, err := oidc.Get(providerName)
+	if err != nil {
+		return model.User{}, err
+	}
+
+	loginState, err := parseOidcState(stateCookie)
+	if err != nil || code == "" ||
+		loginState["provider"] != providerName ||
+		subtle.ConstantTimeCompare([]byte(loginState["state"]), []byte(state)) != 1 {
+		return model.User{}, ErrOidcStateInvalid
+	}
+
+	token, err := provider.Exchange(ctx, code, loginState["verifier"])
+	if err != nil {
+		return model.User{}, err
+	}
+	claims, err := provider.VerifyIDToken(ctx, token.IdToken, loginState["nonce"])
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return resolveOidcUser(provider.Config(), claims)
+}
+
+func parseOidcState(raw string) (map[string]string, error) {
+	if raw == "" {
+		return nil, ErrOidcStateInvalid
+	}
+	token, err := jwt.Parse(raw, jwtkey.Active().Keyfunc)
+	if err != nil || !token.Valid || TokenType(token) != TokenTypeOidcState {
+		return nil, ErrOidcStateInvalid
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	values := make(map[string]string, 4)
+	for _, key := range []string{"provider", "state", "nonce", "verifier"} {
+		value, _ := claims[key].(string)
+		if value == "" {
+			return nil, ErrOidcStateInvalid
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// resolveOidcUser 按 provider+subject 查找已关联的用户；未关联时按配置关联同邮箱用户或创建新用户
+func resolveOidcUser(providerConfig config.OidcProviderConfig, claims *oidc.Claims) (model.User, error) {
+	now := time.Now()
+	identity, err := db.GetIdentity(providerConfig.Name, claims.Subject)
+	if err == nil {
+		if err := db.TouchIdentity(&identity, now); err != nil {
+			return model.User{}, err
+		}
+		return db.GetUserById(identity.UserId.String())
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.User{}, err
+	}
+
+	// 只信任身份提供方声明已验证的邮箱
+	email := ""
+	if claims.EmailVerified {
+		email = strings.TrimSpace(claims.Email)
+	}
+	identity = model.Identity{Provider: providerConfig.Name, Subject: claims.Subject, Email: claims.Email, LastLoginAt: &now}
+
+	// 本地邮箱也必须已验证，否则攻击者可以抢先用受害者的邮箱注册，等受害者第三方登录时关联到攻击者的账号
+	if providerConfig.LinkByEmail && email != "" {
+		existing, err := db.GetUserByEmail(email)
+		if err == nil && existing.EmailVerifiedAt != nil {
+			identity.UserId = existing.Id
+			if err := db.CreateIdentity(&identity); err != nil {
+				return model.User{}, err
+			}
+			return db.GetUserById(existing.Id.String())
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.User{}, err
+		}
+	}
+
+	// 邀请注册模式下第三方登录无法提供邀请码，与关闭注册一样只能关联已有用户
+	mode := config.Current.App.Registration()
+	if !providerConfig.AllowSignup || mode == config.RegistrationDisabled || mode == config.RegistrationInvite {
+		return model.User{}, ErrOidcSignupDisabled
+	}
+
+	username, err := uniqueUsername(oidcUsernameCandidate(providerConfig.Name, claims))
+	if err != nil {
+		return model.User{}, err
+	}
+	user := model.User{Username: username, DisplayName: claims.Name, AvatarUrl: claims.Picture}
+	if mode == config.RegistrationApproval {
+		user.Status = model.StatusPending
+	}
+	if email != "" {
+		taken, err := db.EmailTaken(email, "")
+		if err != nil {
+			return model.User{}, err
+		}
+		if !taken {
+			user.Email = email
+			user.EmailVerifiedAt = &now
+		}
+	}
+
+	// 外部身份创建的用户没有本地密码，登录后可通过修改密码接口直接设置，无需旧密码
+	if err := AssignDefaultRoles(&user, nil); err != nil {
+		return model.User{}, err
+	}
+	if err := db.CreateUserWithIdentity(&user, &identity); err != nil {
+		return model.User{}, err
+	}
+	return db.GetUserById(user.Id.String())
+}
+
+func oidcUsernameCandidate(providerName string, claims *oidc.Claims) string {
+	candidates := []string{claims.PreferredUsername}
+	if at := strings.Index(claims.Email, "@"); at > 0 {
+		candidates = append(candidates, claims.Email[:at])
+	}
+	candidates = append(candidates, providerName+"_"+claims.Subject)
+
+	for _, candidate := range candidates {
+		candidate = usernameDisallowed.ReplaceAllString(candidate, "")
+		if len(candidate) > maxUsernameLen {
+			candidate = candidate[:maxUsernameLen]
+		}
+		if candidate != "" {
+			return candidate
+		}
+	}
+	return providerName + "_user"
+}
+
+// uniqueUsername 用户名被占用时追加随机后缀
+func uniqueUsername(base string) (string, error) {
+	candidate := base
+	for range usernameAttempts {
+		taken, err := db.UsernameTaken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+
+		suffix, err := util.RandomToken(4)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + strings.ToLower(usernameDisallowed.ReplaceAllString(suffix, ""))
+	}
+	return "", errors.New("无法生成唯一用户名")
+}
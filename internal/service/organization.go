@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOrgSlugInvalid   = errors.New("organization slug invalid")
+	ErrOrgSlugTaken     = errors.New("organization slug taken")
+	ErrOrgNameRequired  = errors.New("organization name required")
+	ErrOrgNotMember     = errors.New("not a member of organization")
+	ErrOrgRoleInvalid   = errors.New("organization role invalid")
+	ErrOrgMemberExists  = errors.New("user already a member")
+	ErrOrgLastOwner     = errors.New("organization must keep an owner")
+	ErrOrgOwnerRequired = errors.New("only owners can manage owners")
+)
+
+var orgSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// CreateOrganization 创建组织，创建者成为 owner
+func CreateOrganization(ownerId uuid.UUID, name string, slug string) (model.Membership, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.Membership{}, ErrOrgNameRequired
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !orgSlugPattern.MatchString(slug) {
+		return model.Membership{}, ErrOrgSlugInvalid
+	}
+	taken, err := db.SlugTaken(slug)
+	if err != nil {
+		return model.Membership{}, err
+	}
+	if taken {
+		return model.Membership{}, ErrOrgSlugTaken
+	}
+
+	return db.CreateOrganization(&model.Organization{Name: name, Slug: slug}, ownerId)
+}
+
+// ResolveMembership 校验用户属于指定组织，返回其成员关系
+func ResolveMembership(userId string, organizationId uuid.UUID) (model.Membership, error) {
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return model.Membership{}, ErrOrgNotMember
+	}
+	membership, err := db.GetMembership(organizationId, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Membership{}, ErrOrgNotMember
+		}
+		return model.Membership{}, err
+	}
+	return membership, nil
+}
+
+// SetDefaultOrganization 切换默认组织，之后签发的 token 在 tid 声明中携带该组织
+func SetDefaultOrganization(userId uuid.UUID, organizationId uuid.UUID) error {
+	updated, err := db.SetDefaultMembership(userId, organizationId)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrOrgNotMember
+	}
+	return nil
+}
+
+// AddOrganizationMember 按用户名把用户加入组织，只有 owner 可以添加 owner
+func AddOrganizationMember(actor model.Membership, username string, role string) (model.Membership, error) {
+	if err := checkOrgRoleChange(actor, role); err != nil {
+		return model.Membership{}, err
+	}
+
+	var user model.User
+	if err := db.DB.Where("username = ?", strings.TrimSpace(username)).First(&user).Error; err != nil {
+		return model.Membership{}, err
+	}
+	if _, err := db.GetMembership(actor.OrganizationId, user.Id); err == nil {
+		return model.Membership{}, ErrOrgMemberExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return model.Membership{}, err
+	}
+
+	membership := model.Membership{OrganizationId: actor.OrganizationId, UserId: user.Id, Role: role}
+	if err := db.AddMembership(&membership); err != nil {
+		return model.Membership{}, err
+	}
+	membership.User = &user
+	return membership, nil
+}
+
+// UpdateOrganizationMemberRole 修改成员在组织内的角色，组织至少保留一个 owner
+func UpdateOrganizationMemberRole(actor model.Membership, userId string, role string) (model.Membership, error) {
+	if err := checkOrgRoleChange(actor, role); err != nil {
+		return model.Membership{}, err
+	}
+	membership, err := organizationMember(actor, userId)
+	if err != nil {
+		return model.Membership{}, err
+	}
+	if err := checkOwnerRemovable(actor, membership); err != nil && role != model.OrgRoleOwner {
+		return model.Membership{}, err
+	}
+
+	if err := db.UpdateMembershipRole(&membership, role); err != nil {
+		return model.Membership{}, err
+	}
+	membership.Role = role
+	return membership, nil
+}
+
+// RemoveOrganizationMember 把成员移出组织，组织至少保留一个 owner
+func RemoveOrganizationMember(actor model.Membership, userId string) error {
+	membership, err := organizationMember(actor, userId)
+	if err != nil {
+		return err
+	}
+	if err := checkOwnerRemovable(actor, membership); err != nil {
+		return err
+	}
+	return db.DeleteMembership(&membership)
+}
+
+func organizationMember(actor model.Membership, userId string) (model.Membership, error) {
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return model.Membership{}, ErrOrgNotMember
+	}
+	membership, err := db.GetMembership(actor.OrganizationId, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.Membership{}, ErrOrgNotMember
+		}
+		return model.Membership{}, err
+	}
+	return membership, nil
+}
+
+func checkOrgRoleChange(actor model.Membership, role string) error {
+	if !slices.Contains(model.OrgRoles, role) {
+		return ErrOrgRoleInvalid
+	}
+	if role == model.OrgRoleOwner && actor.Role != model.OrgRoleOwner {
+		return ErrOrgOwnerRequired
+	}
+	return nil
+}
+
+// checkOwnerRemovable owner 只能由 owner 调整，且不能移除最后一个 owner
+func checkOwnerRemovable(actor model.Membership, membership model.Membership) error {
+	if membership.Role != model.OrgRoleOwner {
+		return nil
+	}
+	if actor.Role != model.OrgRoleOwner {
+		return ErrOrgOwnerRequired
+	}
+	owners, err := db.CountOrganizationOwners(membership.OrganizationId)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrOrgLastOwner
+	}
+	return nil
+}
+
+// defaultTenantId 用户默认组织，写入 access token 的 tid 声明
+func defaultTenantId(userId uuid.UUID) (string, error) {
+	membership, err := db.GetDefaultMembership(userId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return membership.OrganizationId.String(), nil
+}
+
+// TokenTenantId 读取 token 中的 tid 声明
+func TokenTenantId(token *jwt.Token) string {
+	if token == nil {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	tenantId, _ := claims["tid"].(string)
+	return tenantId
+}
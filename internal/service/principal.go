@@ -0,0 +1,92 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+const (
+	PrincipalUser   = "user"
+	PrincipalApiKey = "api_key"
+	// PrincipalImpersonation 管理员通过模拟登录 token 以目标用户身份访问
+	PrincipalImpersonation = "impersonation"
+	// PrincipalClient 机器客户端通过 client credentials 授权访问，没有对应的用户
+	PrincipalClient = "client"
+
+	principalLocalsKey = "principal"
+)
+
+// Principal 当前请求的调用方，由 Auth 中间件根据 JWT 或 API key 解析
+type Principal struct {
+	Kind   string
+	UserId string
+	// Username 用户名，机器客户端为客户端名称
+	Username string
+	Roles    []string
+	// Scopes API key 和机器客户端使用，限制可使用的权限
+	Scopes   []string
+	ApiKeyId uuid.UUID
+	// ClientId 机器客户端的 client_id，此时 UserId 为空
+	ClientId string
+	// SessionId 登录会话，仅用户 token 且签发时带有 sid 时存在
+	SessionId string
+	// TenantId 当前请求所在的组织，由 Tenant 中间件校验成员关系后写入
+	TenantId   uuid.UUID
+	TenantRole string
+	// ImpersonatorId 模拟登录时实际操作的管理员，UserId 为被模拟的用户
+	ImpersonatorId   string
+	ImpersonatorName string
+	// Locale 用户偏好的语言，用于选择接口提示的语言
+	Locale string
+}
+
+// HasTenant 当前请求是否已选定组织
+func (p *Principal) HasTenant() bool {
+	return p.TenantId != uuid.Nil
+}
+
+// AllowsScope API key 和机器客户端只能使用授予的权限，其他调用方不受限制
+func (p *Principal) AllowsScope(permission string) bool {
+	if p.Kind != PrincipalApiKey && p.Kind != PrincipalClient {
+		return true
+	}
+	return slices.Contains(p.Scopes, permission)
+}
+
+// HasPermission 调用方是否拥有指定权限：API key 和机器客户端需被授予对应 scope，机器客户端没有角色，权限完全由 scope 决定
+func (p *Principal) HasPermission(permission string) (bool, error) {
+	if !p.AllowsScope(permission) {
+		return false, nil
+	}
+	if p.IsClient() {
+		return true, nil
+	}
+	return HasPermission(p.Roles, permission)
+}
+
+// IsSession 是否为通过登录获得的用户 token
+func (p *Principal) IsSession() bool {
+	return p.Kind == PrincipalUser
+}
+
+// IsClient 是否为机器客户端
+func (p *Principal) IsClient() bool {
+	return p.Kind == PrincipalClient
+}
+
+// IsImpersonated 是否为管理员模拟登录
+func (p *Principal) IsImpersonated() bool {
+	return p.Kind == PrincipalImpersonation
+}
+
+func SetPrincipal(c fiber.Ctx, principal *Principal) {
+	c.Locals(principalLocalsKey, principal)
+}
+
+// PrincipalFrom 读取当前请求的调用方，未经过 Auth 中间件时返回 nil
+func PrincipalFrom(c fiber.Ctx) *Principal {
+	principal, _ := c.Locals(principalLocalsKey).(*Principal)
+	return principal
+}
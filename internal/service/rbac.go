@@ -0,0 +1,131 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// permissionCacheTTL 角色权限缓存时间，调整角色权限后最多延迟这么久生效
+const permissionCacheTTL = time.Minute
+
+type permissionEntry struct {
+	codes     []string
+	expiresAt time.Time
+}
+
+type permissionCache struct {
+	mu      sync.RWMutex
+	entries map[string]permissionEntry
+}
+
+var rolePermissions = &permissionCache{entries: make(map[string]permissionEntry)}
+
+func (p *permissionCache) get(role string, now time.Time) ([]string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	entry, ok := p.entries[role]
+	if !ok || now.After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.codes, true
+}
+
+func (p *permissionCache) set(role string, codes []string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries[role] = permissionEntry{codes: codes, expiresAt: now.Add(permissionCacheTTL)}
+}
+
+// InvalidatePermissionCache 角色权限变更后清空缓存
+func InvalidatePermissionCache() {
+	rolePermissions.mu.Lock()
+	defer rolePermissions.mu.Unlock()
+
+	rolePermissions.entries = make(map[string]permissionEntry)
+}
+
+// HasPermission 判断角色集合是否拥有指定权限
+func HasPermission(roles []string, permission string) (bool, error) {
+	now := time.Now()
+	for _, role := range roles {
+		codes, ok := rolePermissions.get(role, now)
+		if !ok {
+			var err error
+			codes, err = db.GetPermissionCodesByRoles([]string{role})
+			if err != nil {
+				return false, err
+			}
+			rolePermissions.set(role, codes, now)
+		}
+		if slices.Contains(codes, permission) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// AssignDefaultRoles 在创建用户之前设置其初始角色：rbac.defaultRole，配置在 rbac.adminUsers 中的用户额外获得 admin 角色，
+// 以及邀请码附带的角色（invite 可为 nil）。角色写入 user.Roles，随用户在同一事务中创建，分配失败时不会留下没有角色的用户
+func AssignDefaultRoles(user *model.User, invite *model.Invite) error {
+	names := make([]string, 0, 3)
+	add := func(name string) {
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	add(config.Current.Rbac.DefaultRole)
+	if slices.Contains(config.Current.Rbac.AdminUsers, user.Username) {
+		add(model.RoleAdmin)
+	}
+	if invite != nil {
+		add(invite.Role)
+	}
+
+	roles, err := db.GetRolesByNames(names)
+	if err != nil {
+		return err
+	}
+	user.Roles = roles
+	return nil
+}
+
+// TokenRoles 读取 token 中的 roles 声明
+func TokenRoles(token *jwt.Token) []string {
+	if token == nil {
+		return nil
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+
+	raw, ok := claims["roles"].([]interface{})
+	if !ok {
+		return nil
+	}
+	roles := make([]string, 0, len(raw))
+	for _, value := range raw {
+		if role, ok := value.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func roleNames(roles []model.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// revocationCacheTTL 未吊销结果和用户 token 版本的本地缓存时间，多实例部署时吊销最多延迟这么久生效
+const revocationCacheTTL = 30 * time.Second
+
+var ErrTokenRevoked = errors.New("token revoked")
+
+type userTokenState struct {
+	version   int
+	active    bool
+	expiresAt time.Time
+}
+
+// revocationCache 吊销列表的内存缓存，数据库为最终数据源
+type revocationCache struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time      // jti -> token 过期时间
+	checked map[string]time.Time      // 已确认未吊销的 jti -> 缓存到期时间
+	users   map[string]userTokenState // userId -> token 版本与启用状态
+}
+
+var revocations = newRevocationCache()
+
+func newRevocationCache() *revocationCache {
+	return &revocationCache{
+		revoked: make(map[string]time.Time),
+		checked: make(map[string]time.Time),
+		users:   make(map[string]userTokenState),
+	}
+}
+
+func (r *revocationCache) lookupToken(jti string, now time.Time) (revoked bool, known bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.revoked[jti]; ok {
+		return true, true
+	}
+	if expiresAt, ok := r.checked[jti]; ok && now.Before(expiresAt) {
+		return false, true
+	}
+	return false, false
+}
+
+func (r *revocationCache) storeToken(jti string, revoked bool, expiresAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if revoked {
+		r.revoked[jti] = expiresAt
+		delete(r.checked, jti)
+		return
+	}
+	r.checked[jti] = expiresAt
+}
+
+func (r *revocationCache) lookupUser(userId string, now time.Time) (userTokenState, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	state, ok := r.users[userId]
+	if !ok || now.After(state.expiresAt) {
+		return userTokenState{}, false
+	}
+	return state, true
+}
+
+func (r *revocationCache) storeUser(userId string, state userTokenState, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.expiresAt = now.Add(revocationCacheTTL)
+	r.users[userId] = state
+}
+
+// forgetUser 用户 token 版本或状态变化后丢弃缓存，下次校验时重新读取
+func (r *revocationCache) forgetUser(userId string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, userId)
+}
+
+func (r *revocationCache) purge(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for jti, expiresAt := range r.revoked {
+		if now.After(expiresAt) {
+			delete(r.revoked, jti)
+		}
+	}
+	for jti, expiresAt := range r.checked {
+		if now.After(expiresAt) {
+			delete(r.checked, jti)
+		}
+	}
+	for userId, state := range r.users {
+		if now.After(state.expiresAt) {
+			delete(r.users, userId)
+		}
+	}
+}
+
+// LoadRevocations 启动时将数据库中尚未过期的吊销记录载入缓存
+func LoadRevocations() error {
+	now := time.Now()
+	tokens, err := db.ListActiveRevokedTokens(now)
+	if err != nil {
+		return err
+	}
+
+	for _, token := range tokens {
+		revocations.storeToken(token.Jti, true, token.ExpiresAt)
+	}
+	return nil
+}
+
+// StartRevocationCleanup 定期清理已过期的吊销记录和会话
+func StartRevocationCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			now := time.Now()
+			revocations.purge(now)
+			purgeSessionTouches(now)
+			if err := db.DeleteExpiredRevokedTokens(now); err != nil {
+				logger.Error("清理过期吊销记录失败: %v", err)
+			}
+			if err := db.DeleteExpiredSessions(now); err != nil {
+				logger.Error("清理过期会话失败: %v", err)
+			}
+		}
+	}()
+}
+
+// RevokeToken 吊销单个 access token，直到其自然过期
+func RevokeToken(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("invalid jwt claims")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return errors.New("jti claim missing")
+	}
+
+	userId, err := parseUserIDClaim(claims)
+	if err != nil {
+		return err
+	}
+	parsedUserId, err := uuid.Parse(userId)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(revocationCacheTTL)
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		expiresAt = exp.Time
+	}
+
+	if err := db.CreateRevokedToken(&model.RevokedToken{
+		Jti:       jti,
+		UserId:    parsedUserId,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	revocations.storeToken(jti, true, expiresAt)
+	return nil
+}
+
+// RevokeAllUserTokens 使用户此前签发的全部 access token 与 refresh token 失效，并结束全部会话
+func RevokeAllUserTokens(userId uuid.UUID) error {
+	if err := db.IncrementUserTokenVersion(userId); err != nil {
+		return err
+	}
+	revocations.forgetUser(userId.String())
+
+	now := time.Now()
+	if err := db.RevokeUserRefreshTokens(userId, now); err != nil {
+		return err
+	}
+	return db.RevokeUserSessions(userId, now)
+}
+
+// ForgetUserTokenState 用户状态变化后丢弃本地缓存，使变更立即生效
+func ForgetUserTokenState(userId uuid.UUID) {
+	revocations.forgetUser(userId.String())
+}
+
+// CheckTokenRevoked 检查 token 是否已被吊销或所属用户已被禁用，是则返回 ErrTokenRevoked
+func CheckTokenRevoked(token *jwt.Token) error {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("invalid jwt claims")
+	}
+
+	now := time.Now()
+	if jti, _ := claims["jti"].(string); jti != "" {
+		revoked, err := isRevoked(jti, claims, now, func() (bool, error) {
+			return db.IsTokenRevoked(jti)
+		})
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+	if sessionId, _ := claims["sid"].(string); sessionId != "" {
+		revoked, err := isRevoked(sessionCacheKey(sessionId), claims, now, func() (bool, error) {
+			return db.IsSessionRevoked(sessionId)
+		})
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return ErrTokenRevoked
+		}
+	}
+
+	userId, err := parseUserIDClaim(claims)
+	if err != nil {
+		return err
+	}
+	if err := checkUserTokenState(userId, parseVersionClaim(claims), now); err != nil {
+		return err
+	}
+
+	// 模拟登录的 token 在管理员被禁用或吊销全部 token 后同样失效
+	if act, ok := claims["act"].(map[string]interface{}); ok {
+		actorId, _ := act["sub"].(string)
+		if actorId == "" {
+			return ErrTokenRevoked
+		}
+		return checkUserTokenState(actorId, parseVersionClaim(act), now)
+	}
+	return nil
+}
+
+// checkUserTokenState 用户已被禁用、删除，或 token 版本低于用户当前版本时返回 ErrTokenRevoked
+func checkUserTokenState(userId string, version int, now time.Time) error {
+	state, ok := revocations.lookupUser(userId, now)
+	if !ok {
+		current, status, err := db.GetUserTokenState(userId)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTokenRevoked
+			}
+			return err
+		}
+		state = userTokenState{version: current, active: (&model.User{Status: status}).IsActive()}
+		revocations.storeUser(userId, state, now)
+	}
+
+	if !state.active || version < state.version {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// isRevoked 先查本地缓存，未命中时通过 lookup 查询数据库；吊销结果缓存到 token 过期，未吊销结果缓存 revocationCacheTTL
+func isRevoked(key string, claims jwt.MapClaims, now time.Time, lookup func() (bool, error)) (bool, error) {
+	if revoked, known := revocations.lookupToken(key, now); known {
+		return revoked, nil
+	}
+
+	revoked, err := lookup()
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		expiresAt := now.Add(revocationCacheTTL)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt = exp.Time
+		}
+		revocations.storeToken(key, true, expiresAt)
+	} else {
+		revocations.storeToken(key, false, now.Add(revocationCacheTTL))
+	}
+	return revoked, nil
+}
+
+func parseVersionClaim(claims map[string]interface{}) int {
+	switch typed := claims["ver"].(type) {
+	case float64:
+		return int(typed)
+	case int:
+		return typed
+	default:
+		return 0
+	}
+}
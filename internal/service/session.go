@@ -0,0 +1,194 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-starter/internal/model/base"
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// sessionTouchInterval 同一会话两次写入最近活跃时间的最小间隔
+	sessionTouchInterval = time.Minute
+	maxUserAgentLength   = 512
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ClientInfo 发起请求的客户端信息，记录在会话中
+type ClientInfo struct {
+	UserAgent string
+	Ip        string
+}
+
+func ClientInfoFrom(c fiber.Ctx) ClientInfo {
+	return ClientInfo{UserAgent: c.Get(fiber.HeaderUserAgent), Ip: c.IP()}
+}
+
+var (
+	sessionTouchMu sync.Mutex
+	// sessionTouches sessionId -> 上次写入最近活跃时间
+	sessionTouches = make(map[string]time.Time)
+)
+
+func startSession(userId uuid.UUID, sessionId uuid.UUID, jti string, client ClientInfo, expiresAt time.Time) error {
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+
+	now := time.Now()
+	return db.CreateSession(&model.Session{
+		BaseModel:  base.BaseModel{Id: sessionId},
+		UserId:     userId,
+		Device:     describeDevice(userAgent),
+		UserAgent:  userAgent,
+		Ip:         client.Ip,
+		Jti:        jti,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	})
+}
+
+// ListSessions 返回用户当前有效的会话
+func ListSessions(userId uuid.UUID) ([]model.Session, error) {
+	return db.ListUserSessions(userId, time.Now())
+}
+
+// RevokeUserSession 吊销属于用户的会话，该会话的 access token 与 refresh token 立即失效
+func RevokeUserSession(userId uuid.UUID, sessionId string) error {
+	session, err := db.GetUserSession(userId, sessionId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	return revokeSession(session.Id, time.Now())
+}
+
+// revokeSession 吊销会话及其 refresh token family，并在本地缓存中标记，使该会话的 access token 立即失效
+func revokeSession(sessionId uuid.UUID, now time.Time) error {
+	if err := db.RevokeRefreshTokenFamily(sessionId, now); err != nil {
+		return err
+	}
+	if _, err := db.RevokeSession(sessionId, now); err != nil {
+		return err
+	}
+
+	// 会话吊销后无法再刷新，缓存到最后一个 access token 过期即可
+	revocations.storeToken(sessionCacheKey(sessionId.String()), true, now.Add(config.Current.Jwt.AccessTTL()))
+	return nil
+}
+
+// TokenSessionId 返回 access token 所属的会话，会话管理上线前签发的 token 没有 sid
+func TokenSessionId(token *jwt.Token) string {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	sessionId, _ := claims["sid"].(string)
+	return sessionId
+}
+
+// TouchSession 更新会话最近活跃时间和 IP，同一会话每 sessionTouchInterval 最多写一次数据库
+func TouchSession(sessionId string, client ClientInfo) {
+	if sessionId == "" {
+		return
+	}
+
+	now := time.Now()
+	sessionTouchMu.Lock()
+	if last, ok := sessionTouches[sessionId]; ok && now.Sub(last) < sessionTouchInterval {
+		sessionTouchMu.Unlock()
+		return
+	}
+	sessionTouches[sessionId] = now
+	sessionTouchMu.Unlock()
+
+	if err := db.TouchSession(sessionId, client.Ip, now); err != nil {
+		logger.Error("更新会话活跃时间失败: %v", err)
+	}
+}
+
+func purgeSessionTouches(now time.Time) {
+	sessionTouchMu.Lock()
+	defer sessionTouchMu.Unlock()
+
+	for sessionId, last := range sessionTouches {
+		if now.Sub(last) > sessionTouchInterval {
+			delete(sessionTouches, sessionId)
+		}
+	}
+}
+
+func sessionCacheKey(sessionId string) string {
+	return "sid:" + sessionId
+}
+
+// describeDevice 从 User-Agent 中提取浏览器和操作系统，用于在会话列表中辨认设备；没有 User-Agent 时为空，由接口按请求的语言显示
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	browser := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"PostmanRuntime/", "Postman"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	os := ""
+	for _, candidate := range []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, candidate.token) {
+			os = candidate.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	}
+	if len(userAgent) > 64 {
+		return userAgent[:64]
+	}
+	return userAgent
+}
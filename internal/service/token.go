@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/logger"
+	"go-fiber-starter/pkg/util"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const refreshTokenBytes = 32
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+// TokenPair 登录和刷新时返回给客户端的 token 组合
+type TokenPair struct {
+	// Token 与 RefreshToken 在 cookie 会话模式下写入 cookie，响应中为空
+	Token            string `json:"token,omitempty"`
+	TokenType        string `json:"tokenType"`
+	ExpiresIn        int64  `json:"expiresIn"`
+	RefreshToken     string `json:"refreshToken,omitempty"`
+	RefreshExpiresIn int64  `json:"refreshExpiresIn"`
+	// MfaSetupRequired 为 true 时 access token 只能访问两步验证绑定相关接口
+	MfaSetupRequired bool `json:"mfaSetupRequired,omitempty"`
+}
+
+// IssueTokenPair 为用户签发 access token，并开启一个新的会话；会话 Id 同时作为 refresh token family
+func IssueTokenPair(user *model.User, client ClientInfo) (TokenPair, error) {
+	sessionId := uuid.New()
+	pair, refreshToken, jti, err := issueTokenPair(user, sessionId)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	if err := startSession(user.Id, sessionId, jti, client, refreshToken.ExpiresAt); err != nil {
+		return TokenPair{}, err
+	}
+	return pair, nil
+}
+
+// RefreshTokenPair 使用 refresh token 换取新的 token 组合，旧 refresh token 随即失效。
+// 已轮换过的 refresh token 再次出现时视为泄露，整个 family 都会被吊销。
+func RefreshTokenPair(rawToken string, client ClientInfo) (TokenPair, error) {
+	stored, err := db.GetRefreshTokenByHash(util.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return TokenPair{}, ErrRefreshTokenInvalid
+		}
+		return TokenPair{}, err
+	}
+
+	now := time.Now()
+	if stored.RevokedAt != nil {
+		return TokenPair{}, ErrRefreshTokenInvalid
+	}
+	if stored.UsedAt != nil {
+		return TokenPair{}, revokeReusedFamily(stored, client, now)
+	}
+	if now.After(stored.ExpiresAt) {
+		return TokenPair{}, ErrRefreshTokenExpired
+	}
+
+	user, err := db.GetUserById(stored.UserId.String())
+	if err != nil || !user.IsActive() {
+		return TokenPair{}, ErrRefreshTokenInvalid
+	}
+
+	pair, next, jti, err := issueTokenPair(&user, stored.FamilyId)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	marked, err := db.MarkRefreshTokenUsed(stored.Id, next.Id, now)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !marked {
+		// 并发请求抢先轮换了同一个 token，同样按重放处理
+		return TokenPair{}, revokeReusedFamily(stored, client, now)
+	}
+
+	rotated, err := db.RotateSession(stored.FamilyId, jti, client.Ip, now, next.ExpiresAt)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if !rotated {
+		// 会话管理上线前登录的 refresh token 没有会话记录，轮换时补建
+		if err := startSession(user.Id, stored.FamilyId, jti, client, next.ExpiresAt); err != nil {
+			return TokenPair{}, err
+		}
+	}
+
+	auditUser(client, &user, AuditEvent{Action: model.AuditTokenRefresh, TargetType: "session", TargetId: stored.FamilyId.String()})
+	return pair, nil
+}
+
+// issueTokenPair 签发属于 familyId 会话的 token 组合，同时返回新的 refresh token 记录和 access token 的 jti；
+// access token 的 tid 声明取用户当前的默认组织，切换默认组织后刷新 token 即可生效
+func issueTokenPair(user *model.User, familyId uuid.UUID) (TokenPair, *model.RefreshToken, string, error) {
+	tenantId, err := defaultTenantId(user.Id)
+	if err != nil {
+		return TokenPair{}, nil, "", err
+	}
+	accessToken, jti, err := generateAccessToken(user, familyId, tenantId)
+	if err != nil {
+		return TokenPair{}, nil, "", err
+	}
+
+	rawRefreshToken, err := util.RandomToken(refreshTokenBytes)
+	if err != nil {
+		return TokenPair{}, nil, "", err
+	}
+
+	refreshTTL := config.Current.Jwt.RefreshTTL()
+	refreshToken := &model.RefreshToken{
+		UserId:    user.Id,
+		FamilyId:  familyId,
+		TokenHash: util.HashToken(rawRefreshToken),
+		ExpiresAt: time.Now().Add(refreshTTL),
+	}
+	if err := db.CreateRefreshToken(refreshToken); err != nil {
+		return TokenPair{}, nil, "", err
+	}
+
+	return TokenPair{
+		Token:            accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(config.Current.Jwt.AccessTTL().Seconds()),
+		RefreshToken:     rawRefreshToken,
+		RefreshExpiresIn: int64(refreshTTL.Seconds()),
+		MfaSetupRequired: MfaEnrollmentRequired(user),
+	}, refreshToken, jti, nil
+}
+
+func revokeReusedFamily(stored model.RefreshToken, client ClientInfo, now time.Time) error {
+	logger.Warn("检测到 refresh token 重放，吊销 token family: user=%s family=%s", stored.UserId, stored.FamilyId)
+	if err := revokeSession(stored.FamilyId, now); err != nil {
+		return err
+	}
+	RecordAudit(client, AuditEvent{
+		Action:     model.AuditTokenRefresh,
+		ActorId:    stored.UserId.String(),
+		TargetType: "session",
+		TargetId:   stored.FamilyId.String(),
+	}.Failed("reused"))
+	return ErrRefreshTokenReused
+}
+
+// RevokeRefreshToken 吊销 refresh token 所在的会话，token 必须属于指定用户
+func RevokeRefreshToken(userId uuid.UUID, rawToken string) error {
+	stored, err := db.GetRefreshTokenByHash(util.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRefreshTokenInvalid
+		}
+		return err
+	}
+	if stored.UserId != userId {
+		return ErrRefreshTokenInvalid
+	}
+
+	return revokeSession(stored.FamilyId, time.Now())
+}
@@ -11,47 +11,142 @@ import (
 	model "go-fiber-starter/internal/model/user"
 	"go-fiber-starter/pkg/config"
 	"go-fiber-starter/pkg/db"
+	"go-fiber-starter/pkg/jwtkey"
+)
+
+var (
+	ErrUserDisabled = errors.New("user disabled")
+	ErrNotUser      = errors.New("principal is not a user")
 )
 
 func GenerateJWT(user *model.User) (string, error) {
+	token, _, err := generateAccessToken(user, uuid.Nil, "")
+	return token, err
+}
+
+// generateAccessToken 签发 access token 并返回其 jti，sessionId 非空时写入 sid 声明关联到会话，tenantId 非空时写入 tid 声明
+func generateAccessToken(user *model.User, sessionId uuid.UUID, tenantId string) (string, string, error) {
+	claims := accessClaims(user, sessionId, tenantId, config.Current.Jwt.AccessTTL())
+	token, err := jwtkey.Active().Sign(claims)
+	return token, claims["jti"].(string), err
+}
+
+// accessClaims 生成 access token 的声明，有效期为 ttl
+func accessClaims(user *model.User, sessionId uuid.UUID, tenantId string, ttl time.Duration) jwt.MapClaims {
 	// 自定义声明：除了标准的 exp，还加载你的业务字段
+	now := time.Now()
 	claims := jwt.MapClaims{
+		"jti":       uuid.NewString(),
+		"typ":       TokenTypeAccess,
 		"user_id":   user.Id,
 		"user_name": user.Username,
-		"exp":       time.Now().Add(time.Duration(config.Current.Jwt.Expiration) * time.Second).Unix(),
+		"roles":     roleNames(user.Roles),
+		"ver":       user.TokenVersion,
+		"iat":       now.Unix(),
+		"exp":       now.Add(ttl).Unix(),
+	}
+	if MfaEnrollmentRequired(user) {
+		// 角色要求两步验证但尚未启用，token 只能用于完成两步验证绑定
+		claims["mfa_setup"] = true
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.Current.Jwt.Secret))
+	if sessionId != uuid.Nil {
+		claims["sid"] = sessionId.String()
+	}
+	if tenantId != "" {
+		claims["tid"] = tenantId
+	}
+	if user.Locale != "" {
+		claims["locale"] = user.Locale
+	}
+	return claims
 }
 
+// CurrentUser 返回当前调用方对应的用户，JWT 与 API key 都会解析到其所属用户；模拟登录时为被模拟的用户，
+// 机器客户端没有对应的用户，返回 ErrNotUser
 func CurrentUser(c fiber.Ctx) (user *model.User, err error) {
-	token := jwtware.FromContext(c)
-	if token == nil {
-		token = tokenFromLocals(c)
+	var userId string
+	if principal := PrincipalFrom(c); principal != nil {
+		if principal.IsClient() {
+			return nil, ErrNotUser
+		}
+		userId = principal.UserId
+	} else {
+		userId, err = userIdFromToken(c)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if token == nil {
-		return nil, errors.New("no jwt token in context")
+
+	dbUser, err := db.GetUserById(userId)
+	if err != nil {
+		return nil, err
 	}
-	if !token.Valid {
-		return nil, errors.New("invalid jwt token")
+	if !dbUser.IsActive() {
+		return nil, ErrUserDisabled
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, errors.New("invalid jwt claims")
+	return &dbUser, nil
+}
+
+// CurrentActor 返回实际发起请求的用户：模拟登录时为管理员，其余情况与 CurrentUser 相同
+func CurrentActor(c fiber.Ctx) (*model.User, error) {
+	principal := PrincipalFrom(c)
+	if principal == nil || !principal.IsImpersonated() {
+		return CurrentUser(c)
 	}
 
-	userId, err := parseUserIDClaim(claims)
+	actor, err := db.GetUserById(principal.ImpersonatorId)
 	if err != nil {
 		return nil, err
 	}
+	if !actor.IsActive() {
+		return nil, ErrUserDisabled
+	}
+	return &actor, nil
+}
 
-	dbUser, err := db.GetUserById(userId)
-	if err != nil {
-		return nil, err
+// CurrentUserId 读取 token 中的 user_id 声明
+func CurrentUserId(token *jwt.Token) (string, error) {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return "", errors.New("invalid jwt claims")
+	}
+	return parseUserIDClaim(claims)
+}
+
+// TokenUsername 读取 token 中的 user_name 声明，仅用于展示和审计
+func TokenUsername(token *jwt.Token) string {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
 	}
+	username, _ := claims["user_name"].(string)
+	return username
+}
 
-	return &dbUser, nil
+// TokenLocale 读取 token 中的 locale 声明，即签发时用户偏好的语言
+func TokenLocale(token *jwt.Token) string {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	locale, _ := claims["locale"].(string)
+	return locale
+}
+
+func userIdFromToken(c fiber.Ctx) (string, error) {
+	token := jwtware.FromContext(c)
+	if token == nil {
+		token = tokenFromLocals(c)
+	}
+	if token == nil {
+		return "", errors.New("no jwt token in context")
+	}
+	if !token.Valid {
+		return "", errors.New("invalid jwt token")
+	}
+
+	return CurrentUserId(token)
 }
 
 func tokenFromLocals(c fiber.Ctx) *jwt.Token {
@@ -0,0 +1,142 @@
+// Package apperror 定义带有稳定错误码、HTTP 状态码与对外提示的应用错误，由全局 ErrorHandler 统一转换为响应；
+// Cause 只用于服务端日志，不会返回给调用方
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 通用错误码，业务错误应使用更具体的错误码
+const (
+	CodeBadRequest   = "bad_request"
+	CodeValidation   = "validation_failed"
+	CodeUnauthorized = "unauthorized"
+	CodeForbidden    = "forbidden"
+	CodeNotFound     = "not_found"
+	CodeConflict     = "conflict"
+	CodeRateLimited  = "rate_limited"
+	CodeInternal     = "internal_error"
+)
+
+// Error 应用错误；Code 供客户端程序判断，Message 可以直接展示给用户，也可以是语言包中的 key，输出时按请求的语言翻译
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+	// Params Message 中占位符的参数
+	Params map[string]interface{}
+	// Details 附加的错误明细，例如逐条的校验失败原因
+	Details interface{}
+	// RetryAfter 大于 0 时响应带上 Retry-After 头
+	RetryAfter time.Duration
+	Cause      error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Message + ": " + e.Cause.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误码相同即视为同一种错误，便于与包级的错误变量比较
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap 返回附带底层原因的副本，不修改包级的错误变量
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.Cause = cause
+	return &copied
+}
+
+// WithDetails 返回附带错误明细的副本
+func (e *Error) WithDetails(details interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// WithMessage 返回替换了对外提示的副本，例如需要带上具体数值的提示
+func (e *Error) WithMessage(message string) *Error {
+	copied := *e
+	copied.Message = message
+	return &copied
+}
+
+// WithParams 返回附带占位符参数的副本
+func (e *Error) WithParams(params map[string]interface{}) *Error {
+	copied := *e
+	copied.Params = params
+	return &copied
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func Validation(code, message string) *Error {
+	return New(http.StatusBadRequest, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(http.StatusForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(http.StatusNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(http.StatusConflict, code, message)
+}
+
+func RateLimited(code, message string, retryAfter time.Duration) *Error {
+	err := New(http.StatusTooManyRequests, code, message)
+	err.RetryAfter = retryAfter
+	return err
+}
+
+// Internal 服务端错误，对外只给出通用提示，cause 仅写入日志
+func Internal(cause error) *Error {
+	return &Error{Code: CodeInternal, Status: http.StatusInternalServerError, Message: "error.internal", Cause: cause}
+}
+
+// FromStatus 按 HTTP 状态码生成通用错误，错误码取状态码标准描述的 snake_case 形式；5xx 的 message 会被替换为通用提示
+func FromStatus(status int, message string) *Error {
+	if status >= http.StatusInternalServerError {
+		err := Internal(nil)
+		err.Status = status
+		return err
+	}
+	code := strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(http.StatusText(status)))
+	if code == "" {
+		code = CodeBadRequest
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return New(status, code, message)
+}
+
+// As 取出错误链中的 *Error
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,48 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestWrapKeepsSentinelIntact(t *testing.T) {
+	sentinel := NotFound("user_not_found", "用户未找到")
+	cause := errors.New("record not found")
+
+	wrapped := fmt.Errorf("lookup: %w", sentinel.Wrap(cause))
+	if !errors.Is(wrapped, sentinel) {
+		t.Fatalf("expected wrapped error to match sentinel by code")
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Fatalf("expected cause to stay in the error chain")
+	}
+	if sentinel.Cause != nil {
+		t.Fatalf("Wrap must not modify the sentinel")
+	}
+
+	appErr, ok := As(wrapped)
+	if !ok || appErr.Status != http.StatusNotFound || appErr.Code != "user_not_found" {
+		t.Fatalf("unexpected error: %+v", appErr)
+	}
+}
+
+func TestFromStatus(t *testing.T) {
+	cases := []struct {
+		status  int
+		input   string
+		code    string
+		message string
+	}{
+		{http.StatusNotFound, "Cannot GET /x", "not_found", "Cannot GET /x"},
+		{http.StatusMethodNotAllowed, "", "method_not_allowed", "Method Not Allowed"},
+		{http.StatusServiceUnavailable, "upstream down", CodeInternal, "error.internal"},
+	}
+	for _, tc := range cases {
+		err := FromStatus(tc.status, tc.input)
+		if err.Status != tc.status || err.Code != tc.code || err.Message != tc.message {
+			t.Fatalf("FromStatus(%d) = %+v", tc.status, err)
+		}
+	}
+}
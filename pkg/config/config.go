@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -14,16 +15,445 @@ type Config struct {
 	App      AppConfig
 	Jwt      JwtConfig
 	Database DatabaseConfig
+	Rbac     RbacConfig
+	Mail     MailConfig
+	Mfa      MfaConfig
+	Oidc     OidcConfig
+	Security SecurityConfig
+	Audit    AuditConfig
+	Cookie   CookieConfig
+	I18n     I18nConfig
 }
 
 type AppConfig struct {
 	Port string `mapstructure:"port"`
 	Env  string `mapstructure:"env"`
+	// RegistrationMode 注册模式：open/disabled/invite/approval
+	RegistrationMode string `mapstructure:"registrationMode"`
+	// ResponseMode 默认响应模式：legacy/problem，路由组可以通过 middleware.ResponseMode 单独指定
+	ResponseMode string `mapstructure:"responseMode"`
+}
+
+const (
+	// ResponseLegacy HTTP 状态码始终为 200，错误码只写在统一响应结构的 code 字段中
+	ResponseLegacy = "legacy"
+	// ResponseProblem 使用真实的 HTTP 状态码，错误按 RFC 7807 以 application/problem+json 返回
+	ResponseProblem = "problem"
+)
+
+// Responses 返回默认响应模式，未配置或无法识别时为 legacy
+func (c AppConfig) Responses() string {
+	if strings.EqualFold(strings.TrimSpace(c.ResponseMode), ResponseProblem) {
+		return ResponseProblem
+	}
+	return ResponseLegacy
+}
+
+const (
+	// RegistrationOpen 任何人都可以注册
+	RegistrationOpen = "open"
+	// RegistrationDisabled 关闭注册，只能由管理员邀请或第三方账号关联已有用户
+	RegistrationDisabled = "disabled"
+	// RegistrationInvite 注册时必须提供管理员创建的一次性邀请码
+	RegistrationInvite = "invite"
+	// RegistrationApproval 注册后账号处于待审核状态，管理员审核通过后才能登录
+	RegistrationApproval = "approval"
+)
+
+// Registration 返回注册模式，未配置时为 open，无法识别的值按 disabled 处理
+func (c AppConfig) Registration() string {
+	mode := strings.TrimSpace(strings.ToLower(c.RegistrationMode))
+	switch mode {
+	case "":
+		return RegistrationOpen
+	case RegistrationOpen, RegistrationDisabled, RegistrationInvite, RegistrationApproval:
+		return mode
+	default:
+		return RegistrationDisabled
+	}
 }
 
 type JwtConfig struct {
-	Secret     string `mapstructure:"secret"`
-	Expiration int    `mapstructure:"expiration"`
+	Secret string `mapstructure:"secret"`
+	// Expiration 旧版单一 token 有效期（秒），未配置 accessExpiration 时作为 access token 有效期
+	Expiration        int `mapstructure:"expiration"`
+	AccessExpiration  int `mapstructure:"accessExpiration"`
+	RefreshExpiration int `mapstructure:"refreshExpiration"`
+	// ClientExpiration 机器客户端通过 client credentials 获取的 token 有效期（秒）
+	ClientExpiration int `mapstructure:"clientExpiration"`
+	// SigningKid 当前用于签名的密钥 kid，为空时使用 keys 中第一个带私钥的密钥
+	SigningKid string `mapstructure:"signingKid"`
+	// Keys 非对称签名密钥，为空时回退到 HS256 + secret
+	Keys []JwtKeyConfig `mapstructure:"keys"`
+}
+
+// JwtKeyConfig 单个非对称签名密钥，只配置公钥的密钥仅用于验签
+type JwtKeyConfig struct {
+	Kid            string `mapstructure:"kid"`
+	Algorithm      string `mapstructure:"algorithm"` // RS256/ES256/EdDSA
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	PublicKeyFile  string `mapstructure:"publicKeyFile"`
+}
+
+const (
+	defaultAccessExpiration  = 15 * time.Minute
+	defaultRefreshExpiration = 30 * 24 * time.Hour
+	defaultClientExpiration  = time.Hour
+)
+
+// AccessTTL 返回 access token 有效期
+func (c JwtConfig) AccessTTL() time.Duration {
+	if c.AccessExpiration > 0 {
+		return time.Duration(c.AccessExpiration) * time.Second
+	}
+	if c.Expiration > 0 {
+		return time.Duration(c.Expiration) * time.Second
+	}
+	return defaultAccessExpiration
+}
+
+// RefreshTTL 返回 refresh token 有效期
+func (c JwtConfig) RefreshTTL() time.Duration {
+	if c.RefreshExpiration > 0 {
+		return time.Duration(c.RefreshExpiration) * time.Second
+	}
+	return defaultRefreshExpiration
+}
+
+// ClientTTL 返回机器客户端 token 有效期
+func (c JwtConfig) ClientTTL() time.Duration {
+	return secondsOr(c.ClientExpiration, defaultClientExpiration)
+}
+
+type RbacConfig struct {
+	// DefaultRole 新注册用户默认分配的角色
+	DefaultRole string `mapstructure:"defaultRole"`
+	// AdminUsers 启动及注册时自动授予 admin 角色的用户名
+	AdminUsers []string `mapstructure:"adminUsers"`
+}
+
+type MailConfig struct {
+	Driver    string `mapstructure:"driver"` // smtp/file/memory
+	Host      string `mapstructure:"host"`
+	Port      int    `mapstructure:"port"`
+	Username  string `mapstructure:"username"`
+	Password  string `mapstructure:"password"`
+	TLS       bool   `mapstructure:"tls"` // 使用隐式 TLS 连接，未开启时在服务器支持的情况下自动 STARTTLS
+	From      string `mapstructure:"from"`
+	Dir       string `mapstructure:"dir"` // driver=file 时邮件的写入目录
+	QueueSize int    `mapstructure:"queueSize"`
+	// LinkBaseUrl 邮件中验证、重置链接指向的前端地址
+	LinkBaseUrl string `mapstructure:"linkBaseUrl"`
+}
+
+type MfaConfig struct {
+	// Issuer 验证器应用中显示的服务名称
+	Issuer string `mapstructure:"issuer"`
+	// PendingExpiration 登录第一步返回的 mfa pending token 有效期（秒）
+	PendingExpiration int `mapstructure:"pendingExpiration"`
+}
+
+const (
+	defaultMfaIssuer         = "Go Fiber Starter"
+	defaultPendingExpiration = 5 * time.Minute
+)
+
+func (c MfaConfig) IssuerName() string {
+	if c.Issuer != "" {
+		return c.Issuer
+	}
+	return defaultMfaIssuer
+}
+
+// PendingTTL 返回 mfa pending token 有效期
+func (c MfaConfig) PendingTTL() time.Duration {
+	if c.PendingExpiration > 0 {
+		return time.Duration(c.PendingExpiration) * time.Second
+	}
+	return defaultPendingExpiration
+}
+
+type OidcConfig struct {
+	Providers []OidcProviderConfig `mapstructure:"providers"`
+}
+
+// OidcProviderConfig 单个 OpenID Connect 身份提供方
+type OidcProviderConfig struct {
+	Name         string   `mapstructure:"name"` // 路由中使用的标识，如 /api/auth/oidc/{name}/login
+	DisplayName  string   `mapstructure:"displayName"`
+	Issuer       string   `mapstructure:"issuer"` // 通过 {issuer}/.well-known/openid-configuration 自动发现端点
+	ClientId     string   `mapstructure:"clientId"`
+	ClientSecret string   `mapstructure:"clientSecret"`
+	RedirectUrl  string   `mapstructure:"redirectUrl"` // 需在身份提供方登记，指向 /api/auth/oidc/{name}/callback
+	Scopes       []string `mapstructure:"scopes"`
+	// AllowSignup 首次登录且无法关联已有账号时自动创建用户
+	AllowSignup bool `mapstructure:"allowSignup"`
+	// LinkByEmail 身份提供方与本地账号都已验证同一邮箱时自动关联，仅应对可信的企业身份提供方开启
+	LinkByEmail bool `mapstructure:"linkByEmail"`
+}
+
+type SecurityConfig struct {
+	Login         LoginProtectionConfig `mapstructure:"login"`
+	Password      PasswordPolicyConfig  `mapstructure:"password"`
+	PasswordHash  PasswordHashConfig    `mapstructure:"passwordHash"`
+	Impersonation ImpersonationConfig   `mapstructure:"impersonation"`
+}
+
+// ImpersonationConfig 管理员模拟登录
+type ImpersonationConfig struct {
+	// Expiration 模拟登录 token 有效期（秒），不签发 refresh token，到期后需重新发起
+	Expiration int `mapstructure:"expiration"`
+}
+
+const defaultImpersonationExpiration = 15 * time.Minute
+
+func (c ImpersonationConfig) TTL() time.Duration {
+	return secondsOr(c.Expiration, defaultImpersonationExpiration)
+}
+
+// LoginProtectionConfig 登录防暴力破解：按账号和 IP 分别计数，达到阈值后按指数退避锁定
+type LoginProtectionConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Store   string `mapstructure:"store"` // memory/db，多实例部署使用 db
+	// AccountThreshold 同一账号连续失败多少次后锁定
+	AccountThreshold int `mapstructure:"accountThreshold"`
+	// IpThreshold 同一 IP 连续失败多少次后锁定
+	IpThreshold int `mapstructure:"ipThreshold"`
+	// Window 失败计数窗口（秒），超过该时间未再失败则重新计数
+	Window int `mapstructure:"window"`
+	// BaseLockout 首次锁定时长（秒），之后每多失败一次翻倍
+	BaseLockout int `mapstructure:"baseLockout"`
+	// MaxLockout 最长锁定时长（秒）
+	MaxLockout int `mapstructure:"maxLockout"`
+}
+
+const (
+	defaultAccountThreshold = 5
+	defaultIpThreshold      = 20
+	defaultLoginWindow      = 15 * time.Minute
+	defaultBaseLockout      = time.Minute
+	defaultMaxLockout       = time.Hour
+)
+
+func (c LoginProtectionConfig) AccountLimit() int {
+	if c.AccountThreshold > 0 {
+		return c.AccountThreshold
+	}
+	return defaultAccountThreshold
+}
+
+func (c LoginProtectionConfig) IpLimit() int {
+	if c.IpThreshold > 0 {
+		return c.IpThreshold
+	}
+	return defaultIpThreshold
+}
+
+func (c LoginProtectionConfig) WindowDuration() time.Duration {
+	return secondsOr(c.Window, defaultLoginWindow)
+}
+
+func (c LoginProtectionConfig) BaseLockoutDuration() time.Duration {
+	return secondsOr(c.BaseLockout, defaultBaseLockout)
+}
+
+func (c LoginProtectionConfig) MaxLockoutDuration() time.Duration {
+	return secondsOr(c.MaxLockout, defaultMaxLockout)
+}
+
+func secondsOr(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// PasswordPolicyConfig 注册、修改密码和重置密码时校验的密码规则
+type PasswordPolicyConfig struct {
+	MinLength int `mapstructure:"minLength"` // 最少字符数，默认 8
+	MaxLength int `mapstructure:"maxLength"` // 最多字节数，bcrypt 只使用前 72 字节，超过 72 按 72 处理
+	// 必须包含的字符类型
+	RequireUpper  bool `mapstructure:"requireUpper"`
+	RequireLower  bool `mapstructure:"requireLower"`
+	RequireDigit  bool `mapstructure:"requireDigit"`
+	RequireSymbol bool `mapstructure:"requireSymbol"`
+	// DisallowUsername 密码中不能包含用户名（不区分大小写）
+	DisallowUsername bool `mapstructure:"disallowUsername"`
+	// BreachedList 离线泄露密码库路径，每行一个按 SHA-1 排序的大写十六进制摘要，可带 ":次数" 后缀；为空时不检查
+	BreachedList string `mapstructure:"breachedList"`
+}
+
+const (
+	defaultPasswordMinLength = 8
+	// bcryptMaxLength bcrypt 会静默截断超过 72 字节的部分
+	bcryptMaxLength = 72
+)
+
+func (c PasswordPolicyConfig) MinLen() int {
+	if c.MinLength > 0 {
+		return c.MinLength
+	}
+	return defaultPasswordMinLength
+}
+
+func (c PasswordPolicyConfig) MaxLen() int {
+	if c.MaxLength > 0 && c.MaxLength < bcryptMaxLength {
+		return c.MaxLength
+	}
+	return bcryptMaxLength
+}
+
+// PasswordHashConfig 新密码使用的哈希算法与参数，已有哈希与配置不一致时在用户下次登录成功后自动升级
+type PasswordHashConfig struct {
+	Algorithm  string `mapstructure:"algorithm"` // argon2id/bcrypt，默认 argon2id
+	BcryptCost int    `mapstructure:"bcryptCost"`
+	// Argon2Memory argon2id 使用的内存（KiB）
+	Argon2Memory      uint32 `mapstructure:"argon2Memory"`
+	Argon2Iterations  uint32 `mapstructure:"argon2Iterations"`
+	Argon2Parallelism uint8  `mapstructure:"argon2Parallelism"`
+}
+
+// argon2id 默认参数取自 OWASP 密码存储建议
+const (
+	defaultBcryptCost        = 10
+	defaultArgon2Memory      = 19 * 1024
+	defaultArgon2Iterations  = 2
+	defaultArgon2Parallelism = 1
+)
+
+func (c PasswordHashConfig) AlgorithmName() string {
+	algorithm := strings.TrimSpace(strings.ToLower(c.Algorithm))
+	if algorithm == "" {
+		return "argon2id"
+	}
+	return algorithm
+}
+
+func (c PasswordHashConfig) BcryptWorkFactor() int {
+	if c.BcryptCost > 0 {
+		return c.BcryptCost
+	}
+	return defaultBcryptCost
+}
+
+func (c PasswordHashConfig) Argon2MemoryKiB() uint32 {
+	if c.Argon2Memory > 0 {
+		return c.Argon2Memory
+	}
+	return defaultArgon2Memory
+}
+
+func (c PasswordHashConfig) Argon2Time() uint32 {
+	if c.Argon2Iterations > 0 {
+		return c.Argon2Iterations
+	}
+	return defaultArgon2Iterations
+}
+
+func (c PasswordHashConfig) Argon2Threads() uint8 {
+	if c.Argon2Parallelism > 0 {
+		return c.Argon2Parallelism
+	}
+	return defaultArgon2Parallelism
+}
+
+// AuditConfig 审计日志异步写入队列与保留期
+type AuditConfig struct {
+	// QueueSize 待写入队列长度，队列已满时丢弃新事件并记录错误日志
+	QueueSize int `mapstructure:"queueSize"`
+	// RetentionDays 审计日志保留天数，0 表示永久保留
+	RetentionDays int `mapstructure:"retentionDays"`
+}
+
+const defaultAuditQueueSize = 1000
+
+func (c AuditConfig) QueueLength() int {
+	if c.QueueSize > 0 {
+		return c.QueueSize
+	}
+	return defaultAuditQueueSize
+}
+
+// Retention 返回审计日志保留时长，0 表示不清理
+func (c AuditConfig) Retention() time.Duration {
+	if c.RetentionDays > 0 {
+		return time.Duration(c.RetentionDays) * 24 * time.Hour
+	}
+	return 0
+}
+
+// CookieConfig 浏览器客户端的 cookie 会话模式：登录后 token 写入 HttpOnly cookie，
+// 通过 cookie 认证的写请求需要携带与 CSRF cookie 相同的请求头（double-submit）
+type CookieConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AccessName access token cookie 名称，对全部路径可见
+	AccessName string `mapstructure:"accessName"`
+	// RefreshName refresh token cookie 名称，只发送到 /api/auth 下的接口
+	RefreshName string `mapstructure:"refreshName"`
+	// CsrfName CSRF token cookie 名称，不设置 HttpOnly，供前端读取后放入 CsrfHeader
+	CsrfName   string `mapstructure:"csrfName"`
+	CsrfHeader string `mapstructure:"csrfHeader"`
+	Domain     string `mapstructure:"domain"`
+	// SameSite Strict/Lax/None，默认 Lax
+	SameSite string `mapstructure:"sameSite"`
+	// Insecure 去掉 Secure 属性，仅用于本地 http 调试
+	Insecure bool `mapstructure:"insecure"`
+}
+
+const (
+	defaultAccessCookie  = "access_token"
+	defaultRefreshCookie = "refresh_token"
+	defaultCsrfCookie    = "csrf_token"
+	defaultCsrfHeader    = "X-CSRF-Token"
+)
+
+func (c CookieConfig) AccessCookie() string {
+	return stringOr(c.AccessName, defaultAccessCookie)
+}
+
+func (c CookieConfig) RefreshCookie() string {
+	return stringOr(c.RefreshName, defaultRefreshCookie)
+}
+
+func (c CookieConfig) CsrfCookie() string {
+	return stringOr(c.CsrfName, defaultCsrfCookie)
+}
+
+func (c CookieConfig) CsrfHeaderName() string {
+	return stringOr(c.CsrfHeader, defaultCsrfHeader)
+}
+
+// SameSiteMode 返回 cookie 的 SameSite 属性，无法识别的值按 Lax 处理
+func (c CookieConfig) SameSiteMode() string {
+	switch strings.ToLower(strings.TrimSpace(c.SameSite)) {
+	case "strict":
+		return "Strict"
+	case "none":
+		return "None"
+	default:
+		return "Lax"
+	}
+}
+
+// I18nConfig 接口提示的多语言配置，内置 zh-CN 与 en 两套语言包
+type I18nConfig struct {
+	// DefaultLocale 请求未指定语言或指定的语言不受支持时使用，默认 zh-CN
+	DefaultLocale string `mapstructure:"defaultLocale"`
+	// Dir 额外的语言包目录，其中的 <locale>.yaml 会覆盖或补充内置语言包
+	Dir string `mapstructure:"dir"`
+}
+
+const defaultLocale = "zh-CN"
+
+func (c I18nConfig) Default() string {
+	return stringOr(c.DefaultLocale, defaultLocale)
+}
+
+func stringOr(value string, fallback string) string {
+	if value = strings.TrimSpace(value); value != "" {
+		return value
+	}
+	return fallback
 }
 
 type DatabaseConfig struct {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadConfigBaseOnly(t *testing.T) {
@@ -95,3 +96,25 @@ func writeConfigFile(t *testing.T, configDir string, fileName string, content st
 		t.Fatalf("WriteFile returned error: %v", err)
 	}
 }
+
+func TestJwtConfigTTLFallback(t *testing.T) {
+	t.Parallel()
+
+	legacy := JwtConfig{Expiration: 3600}
+	if legacy.AccessTTL() != time.Hour {
+		t.Fatalf("legacy AccessTTL %s != 1h", legacy.AccessTTL())
+	}
+
+	explicit := JwtConfig{Expiration: 3600, AccessExpiration: 600, RefreshExpiration: 7200}
+	if explicit.AccessTTL() != 10*time.Minute {
+		t.Fatalf("AccessTTL %s != 10m", explicit.AccessTTL())
+	}
+	if explicit.RefreshTTL() != 2*time.Hour {
+		t.Fatalf("RefreshTTL %s != 2h", explicit.RefreshTTL())
+	}
+
+	empty := JwtConfig{}
+	if empty.AccessTTL() != defaultAccessExpiration || empty.RefreshTTL() != defaultRefreshExpiration {
+		t.Fatalf("unexpected default ttl %s/%s", empty.AccessTTL(), empty.RefreshTTL())
+	}
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+)
+
+func CreateApiKey(key *model.ApiKey) error {
+	return DB.Create(key).Error
+}
+
+func GetApiKeyByHash(hash string) (model.ApiKey, error) {
+	var key model.ApiKey
+	result := DB.First(&key, "key_hash = ?", hash)
+	if result.Error != nil {
+		return key, result.Error
+	}
+
+	return key, nil
+}
+
+// ListUserApiKeys 返回用户未吊销的 API key
+func ListUserApiKeys(userId uuid.UUID) ([]model.ApiKey, error) {
+	var keys []model.ApiKey
+	result := DB.Where("user_id = ? AND revoked_at IS NULL", userId).Order("created_at DESC").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return keys, nil
+}
+
+// RevokeUserApiKey 吊销属于指定用户的 API key，返回 false 表示不存在或已吊销
+func RevokeUserApiKey(userId uuid.UUID, id string, revokedAt time.Time) (bool, error) {
+	result := DB.Model(&model.ApiKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userId).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func TouchApiKey(id uuid.UUID, usedAt time.Time) error {
+	return DB.Model(&model.ApiKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
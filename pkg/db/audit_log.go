@@ -0,0 +1,101 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/query"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件，零值字段不参与过滤
+type AuditLogFilter struct {
+	Action  string
+	Outcome string
+	ActorId string
+	// ImpersonatorId 只返回该管理员模拟登录期间的事件
+	ImpersonatorId string
+	TargetId       string
+	Ip             string
+	From           *time.Time
+	To             *time.Time
+}
+
+func (f AuditLogFilter) apply(q *gorm.DB) *gorm.DB {
+	if f.Action != "" {
+		// 以 . 结尾时按前缀匹配，如 admin. 匹配全部管理操作
+		if f.Action[len(f.Action)-1] == '.' {
+			q = q.Where(query.HasPrefix("action", f.Action))
+		} else {
+			q = q.Where("action = ?", f.Action)
+		}
+	}
+	if f.Outcome != "" {
+		q = q.Where("outcome = ?", f.Outcome)
+	}
+	if f.ActorId != "" {
+		q = q.Where("actor_id = ?", f.ActorId)
+	}
+	if f.ImpersonatorId != "" {
+		q = q.Where("impersonator_id = ?", f.ImpersonatorId)
+	}
+	if f.TargetId != "" {
+		q = q.Where("target_id = ?", f.TargetId)
+	}
+	if f.Ip != "" {
+		q = q.Where("ip = ?", f.Ip)
+	}
+	if f.From != nil {
+		q = q.Where("occurred_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("occurred_at < ?", *f.To)
+	}
+	return q
+}
+
+// CreateAuditLogs 批量写入审计日志
+func CreateAuditLogs(logs []model.AuditLog) error {
+	return DB.Create(&logs).Error
+}
+
+// ListAuditLogs 按条件和 params 中的排序、过滤分页查询审计日志
+func ListAuditLogs(filter AuditLogFilter, params query.Params) ([]model.AuditLog, query.PageInfo, error) {
+	var logs []model.AuditLog
+	info, err := query.Find(filter.apply(DB.Model(&model.AuditLog{})), params, &logs)
+	if err != nil {
+		return nil, query.PageInfo{}, err
+	}
+	return logs, info, nil
+}
+
+// EachAuditLog 按条件和 params 中的排序、过滤分批读取最多 limit 条审计日志，用于导出；params 中的分页参数不生效
+func EachAuditLog(filter AuditLogFilter, params query.Params, limit int, batchSize int, fn func([]model.AuditLog) error) error {
+	for offset := 0; offset < limit; offset += batchSize {
+		var logs []model.AuditLog
+		result := filter.apply(DB.Model(&model.AuditLog{})).
+			Scopes(params.FilterScope(), params.OrderScope()).
+			Offset(offset).
+			Limit(min(batchSize, limit-offset)).
+			Find(&logs)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+		if err := fn(logs); err != nil {
+			return err
+		}
+		if len(logs) < batchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// DeleteAuditLogsBefore 清理超过保留期的审计日志
+func DeleteAuditLogsBefore(before time.Time) error {
+	return DB.Where("occurred_at < ?", before).Delete(&model.AuditLog{}).Error
+}
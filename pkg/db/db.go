@@ -27,6 +27,9 @@ func Init() error {
 	if err := autoMigrate(); err != nil {
 		return fmt.Errorf("数据库迁移失败: %w", err)
 	}
+	if err := seed(); err != nil {
+		return fmt.Errorf("写入默认数据失败: %w", err)
+	}
 
 	return nil
 }
@@ -43,6 +46,9 @@ func openDatabase(databaseConfig config.DatabaseConfig) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
+	if err := RegisterTenantScope(db); err != nil {
+		return nil, fmt.Errorf("注册多租户回调失败: %w", err)
+	}
 
 	return db, nil
 }
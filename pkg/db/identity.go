@@ -0,0 +1,68 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func GetIdentity(provider string, subject string) (model.Identity, error) {
+	var identity model.Identity
+	result := DB.First(&identity, "provider = ? AND subject = ?", provider, subject)
+	if result.Error != nil {
+		return identity, result.Error
+	}
+
+	return identity, nil
+}
+
+func CreateIdentity(identity *model.Identity) error {
+	return DB.Create(identity).Error
+}
+
+// CreateUserWithIdentity 在同一事务中创建用户和外部身份
+func CreateUserWithIdentity(user *model.User, identity *model.Identity) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserId = user.Id
+		return tx.Create(identity).Error
+	})
+}
+
+func TouchIdentity(identity *model.Identity, loginAt time.Time) error {
+	return DB.Model(identity).Update("last_login_at", loginAt).Error
+}
+
+func ListUserIdentities(userId uuid.UUID) ([]model.Identity, error) {
+	var identities []model.Identity
+	result := DB.Where("user_id = ?", userId).Order("created_at").Find(&identities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return identities, nil
+}
+
+// DeleteUserIdentity 删除属于指定用户的外部身份，返回 false 表示记录不存在
+func DeleteUserIdentity(userId uuid.UUID, id string) (bool, error) {
+	result := DB.Where("id = ? AND user_id = ?", id, userId).Delete(&model.Identity{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func UsernameTaken(username string) (bool, error) {
+	var count int64
+	if err := DB.Model(&model.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errInviteUsed = errors.New("invite already used")
+
+func CreateInvite(invite *model.Invite) error {
+	return DB.Create(invite).Error
+}
+
+func GetInviteByHash(hash string) (model.Invite, error) {
+	var invite model.Invite
+	result := DB.First(&invite, "code_hash = ?", hash)
+	if result.Error != nil {
+		return invite, result.Error
+	}
+
+	return invite, nil
+}
+
+// ListInvites 返回邀请码，includeUsed 为 false 时只返回未使用的
+func ListInvites(includeUsed bool) ([]model.Invite, error) {
+	var invites []model.Invite
+	query := DB.Order("created_at DESC")
+	if !includeUsed {
+		query = query.Where("used_at IS NULL")
+	}
+	if err := query.Find(&invites).Error; err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// DeleteUnusedInvite 删除尚未使用的邀请码，返回 false 表示不存在或已被使用
+func DeleteUnusedInvite(id string) (bool, error) {
+	result := DB.Where("id = ? AND used_at IS NULL", id).Delete(&model.Invite{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// CreateUserWithInvite 在同一事务中创建用户并核销邀请码，返回 false 表示邀请码已被并发请求使用，用户不会被创建
+func CreateUserWithInvite(user *model.User, inviteId uuid.UUID, usedAt time.Time) (bool, error) {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&model.Invite{}).
+			Where("id = ? AND used_at IS NULL", inviteId).
+			Updates(map[string]interface{}{"used_at": usedAt, "used_by": user.Id})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errInviteUsed
+		}
+		return nil
+	})
+	if errors.Is(err, errInviteUsed) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/throttle"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptAccountKey 按用户名计数的登录失败记录的 key，用户名不区分大小写
+func LoginAttemptAccountKey(username string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+// ThrottleStore 基于 login_attempts 表的 throttle.Store，多实例部署时共享失败计数
+type ThrottleStore struct{}
+
+func (ThrottleStore) Get(key string) (throttle.Entry, bool, error) {
+	var attempt model.LoginAttempt
+	if err := DB.First(&attempt, "attempt_key = ?", key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return throttle.Entry{}, false, nil
+		}
+		return throttle.Entry{}, false, err
+	}
+
+	return throttle.Entry{
+		Failures:      attempt.Failures,
+		LastFailureAt: attempt.LastFailureAt,
+		LockedUntil:   attempt.LockedUntil,
+	}, true, nil
+}
+
+func (ThrottleStore) Save(key string, entry throttle.Entry) error {
+	attempt := model.LoginAttempt{
+		Key:           key,
+		Failures:      entry.Failures,
+		LastFailureAt: entry.LastFailureAt,
+		LockedUntil:   entry.LockedUntil,
+	}
+	return DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "attempt_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"failures", "last_failure_at", "locked_until", "updated_at"}),
+	}).Create(&attempt).Error
+}
+
+func (ThrottleStore) Delete(key string) error {
+	return DB.Where("attempt_key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
+
+// DeleteStaleLoginAttempts 清理锁定已结束且早于 before 的失败记录
+func DeleteStaleLoginAttempts(before time.Time) error {
+	return DB.Where("last_failure_at < ? AND locked_until < ?", before, time.Now()).Delete(&model.LoginAttempt{}).Error
+}
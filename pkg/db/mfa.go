@@ -0,0 +1,56 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UseTotpStep 记录已使用的验证码步长，返回 false 表示该步长或更晚的验证码已被使用过
+func UseTotpStep(userId uuid.UUID, step int64) (bool, error) {
+	result := DB.Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", userId, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// ReplaceRecoveryCodes 删除用户原有的恢复码并写入新的恢复码
+func ReplaceRecoveryCodes(userId uuid.UUID, codes []model.RecoveryCode) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userId).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode 将匹配的恢复码标记为已使用，返回 false 表示恢复码不存在或已被使用
+func UseRecoveryCode(userId uuid.UUID, hash string, usedAt time.Time) (bool, error) {
+	result := DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, hash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func CountUnusedRecoveryCodes(userId uuid.UUID) (int64, error) {
+	var count int64
+	if err := DB.Model(&model.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
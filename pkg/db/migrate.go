@@ -1,10 +1,34 @@
 package db
 
-import model "go-fiber-starter/internal/model/user"
+import (
+	model "go-fiber-starter/internal/model/user"
+
+	"gorm.io/gorm"
+)
 
 // autoMigrate 自动迁移数据库表
 func autoMigrate() error {
-	return DB.AutoMigrate(
+	return AutoMigrate(DB)
+}
+
+// AutoMigrate 对指定连接执行迁移，测试中的内存数据库也复用这份模型清单
+func AutoMigrate(database *gorm.DB) error {
+	return database.AutoMigrate(
+		&model.Permission{},
+		&model.Role{},
 		&model.User{},
+		&model.RefreshToken{},
+		&model.RevokedToken{},
+		&model.UserToken{},
+		&model.RecoveryCode{},
+		&model.Identity{},
+		&model.ApiKey{},
+		&model.LoginAttempt{},
+		&model.Session{},
+		&model.Invite{},
+		&model.Organization{},
+		&model.Membership{},
+		&model.AuditLog{},
+		&model.OAuthClient{},
 	)
 }
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+)
+
+func CreateOAuthClient(client *model.OAuthClient) error {
+	return DB.Create(client).Error
+}
+
+func GetOAuthClientById(id string) (model.OAuthClient, error) {
+	var client model.OAuthClient
+	result := DB.First(&client, "id = ?", id)
+	if result.Error != nil {
+		return client, result.Error
+	}
+
+	return client, nil
+}
+
+func GetOAuthClientByClientId(clientId string) (model.OAuthClient, error) {
+	var client model.OAuthClient
+	result := DB.First(&client, "client_id = ?", clientId)
+	if result.Error != nil {
+		return client, result.Error
+	}
+
+	return client, nil
+}
+
+// ListOAuthClients 返回未吊销的机器客户端
+func ListOAuthClients() ([]model.OAuthClient, error) {
+	var clients []model.OAuthClient
+	result := DB.Where("revoked_at IS NULL").Order("created_at DESC").Find(&clients)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return clients, nil
+}
+
+// RevokeOAuthClient 吊销机器客户端，返回 false 表示不存在或已吊销
+func RevokeOAuthClient(id string, revokedAt time.Time) (bool, error) {
+	result := DB.Model(&model.OAuthClient{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func TouchOAuthClient(id uuid.UUID, usedAt time.Time) error {
+	return DB.Model(&model.OAuthClient{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
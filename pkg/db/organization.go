@@ -0,0 +1,138 @@
+package db
+
+import (
+	"errors"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateOrganization 创建组织并把创建者加入为 owner，创建者还没有默认组织时设为默认
+func CreateOrganization(organization *model.Organization, ownerId uuid.UUID) (model.Membership, error) {
+	membership := model.Membership{UserId: ownerId, Role: model.OrgRoleOwner}
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		membership.OrganizationId = organization.Id
+		return createMembership(tx, &membership)
+	})
+	membership.Organization = organization
+	return membership, err
+}
+
+func GetOrganization(id uuid.UUID) (model.Organization, error) {
+	var organization model.Organization
+	result := DB.First(&organization, "id = ?", id)
+	if result.Error != nil {
+		return organization, result.Error
+	}
+
+	return organization, nil
+}
+
+func SlugTaken(slug string) (bool, error) {
+	var count int64
+	if err := DB.Model(&model.Organization{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func GetMembership(organizationId uuid.UUID, userId uuid.UUID) (model.Membership, error) {
+	var membership model.Membership
+	result := DB.First(&membership, "organization_id = ? AND user_id = ?", organizationId, userId)
+	if result.Error != nil {
+		return membership, result.Error
+	}
+
+	return membership, nil
+}
+
+// GetDefaultMembership 返回用户的默认组织，没有时返回 gorm.ErrRecordNotFound
+func GetDefaultMembership(userId uuid.UUID) (model.Membership, error) {
+	var membership model.Membership
+	result := DB.First(&membership, "user_id = ? AND is_default = ?", userId, true)
+	if result.Error != nil {
+		return membership, result.Error
+	}
+
+	return membership, nil
+}
+
+// ListUserMemberships 返回用户加入的全部组织
+func ListUserMemberships(userId uuid.UUID) ([]model.Membership, error) {
+	var memberships []model.Membership
+	result := DB.Preload("Organization").Where("user_id = ?", userId).Order("created_at").Find(&memberships)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return memberships, nil
+}
+
+// ListOrganizationMembers 返回组织成员及其用户信息
+func ListOrganizationMembers(organizationId uuid.UUID) ([]model.Membership, error) {
+	var memberships []model.Membership
+	result := DB.Preload("User").Where("organization_id = ?", organizationId).Order("created_at").Find(&memberships)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return memberships, nil
+}
+
+// AddMembership 把用户加入组织，用户还没有默认组织时设为默认
+func AddMembership(membership *model.Membership) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return createMembership(tx, membership)
+	})
+}
+
+func createMembership(tx *gorm.DB, membership *model.Membership) error {
+	var existing model.Membership
+	err := tx.First(&existing, "user_id = ? AND is_default = ?", membership.UserId, true).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		membership.IsDefault = true
+	} else if err != nil {
+		return err
+	}
+	return tx.Create(membership).Error
+}
+
+func UpdateMembershipRole(membership *model.Membership, role string) error {
+	return DB.Model(membership).Update("role", role).Error
+}
+
+func DeleteMembership(membership *model.Membership) error {
+	return DB.Delete(membership).Error
+}
+
+// SetDefaultMembership 把用户的默认组织切换为 organizationId，返回 false 表示用户不是该组织成员
+func SetDefaultMembership(userId uuid.UUID, organizationId uuid.UUID) (bool, error) {
+	updated := false
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Membership{}).
+			Where("user_id = ? AND organization_id = ?", userId, organizationId).
+			Update("is_default", true)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return tx.Model(&model.Membership{}).
+			Where("user_id = ? AND organization_id <> ?", userId, organizationId).
+			Update("is_default", false).Error
+	})
+	return updated, err
+}
+
+func CountOrganizationOwners(organizationId uuid.UUID) (int64, error) {
+	var count int64
+	err := DB.Model(&model.Membership{}).
+		Where("organization_id = ? AND role = ?", organizationId, model.OrgRoleOwner).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateRevokedToken(token *model.RevokedToken) error {
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+func IsTokenRevoked(jti string) (bool, error) {
+	var count int64
+	if err := DB.Model(&model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ListActiveRevokedTokens 返回尚未过期的吊销记录，用于启动时预热缓存
+func ListActiveRevokedTokens(now time.Time) ([]model.RevokedToken, error) {
+	var tokens []model.RevokedToken
+	result := DB.Where("expires_at > ?", now).Find(&tokens)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return tokens, nil
+}
+
+func DeleteExpiredRevokedTokens(now time.Time) error {
+	return DB.Where("expires_at <= ?", now).Delete(&model.RevokedToken{}).Error
+}
+
+// GetUserTokenState 返回用户当前的 token 版本和状态
+func GetUserTokenState(userId string) (int, string, error) {
+	var user model.User
+	result := DB.Select("token_version", "status").First(&user, "id = ?", userId)
+	if result.Error != nil {
+		return 0, "", result.Error
+	}
+
+	return user.TokenVersion, user.Status, nil
+}
+
+// IncrementUserTokenVersion 递增用户的 token 版本
+func IncrementUserTokenVersion(userId uuid.UUID) error {
+	return DB.Model(&model.User{}).Where("id = ?", userId).
+		UpdateColumn("token_version", gorm.Expr("token_version + ?", 1)).Error
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"errors"
+	"slices"
+
+	model "go-fiber-starter/internal/model/user"
+)
+
+// ErrRoleNotFound 传入的角色中有不存在的角色
+var ErrRoleNotFound = errors.New("role not found")
+
+// GetRolesByNames 按名称查询角色，重复的名称只计一次，有任一角色不存在时返回 ErrRoleNotFound
+func GetRolesByNames(names []string) ([]model.Role, error) {
+	var roles []model.Role
+	if len(names) == 0 {
+		return roles, nil
+	}
+
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if !slices.Contains(unique, name) {
+			unique = append(unique, name)
+		}
+	}
+	result := DB.Where("name IN ?", unique).Find(&roles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(roles) != len(unique) {
+		return nil, ErrRoleNotFound
+	}
+
+	return roles, nil
+}
+
+// GetPermissionCodesByRoles 返回角色拥有的权限编码
+func GetPermissionCodesByRoles(names []string) ([]string, error) {
+	var codes []string
+	if len(names) == 0 {
+		return codes, nil
+	}
+
+	result := DB.Model(&model.Permission{}).
+		Distinct("permissions.code").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name IN ?", names).
+		Pluck("permissions.code", &codes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return codes, nil
+}
+
+func AppendUserRoles(user *model.User, roles []model.Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	return DB.Model(user).Association("Roles").Append(roles)
+}
+
+// ListRoles 返回全部角色及其权限
+func ListRoles() ([]model.Role, error) {
+	var roles []model.Role
+	result := DB.Preload("Permissions").Order("name").Find(&roles)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return roles, nil
+}
+
+func GetRoleByName(name string) (model.Role, error) {
+	var role model.Role
+	result := DB.Preload("Permissions").First(&role, "name = ?", name)
+	if result.Error != nil {
+		return role, result.Error
+	}
+
+	return role, nil
+}
+
+func UpdateRoleFields(role *model.Role, fields map[string]interface{}) error {
+	return DB.Model(role).Updates(fields).Error
+}
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"sort"
+
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+var defaultRoleDescriptions = map[string]string{
+	model.RoleAdmin: "管理员",
+	model.RoleUser:  "普通用户",
+}
+
+// seed 写入默认数据
+func seed() error {
+	return Seed(DB)
+}
+
+// Seed 同步内置权限与默认角色，admin 角色始终拥有全部内置权限，可重复执行
+func Seed(database *gorm.DB) error {
+	return database.Transaction(func(tx *gorm.DB) error {
+		codes := make([]string, 0, len(model.Permissions))
+		for code := range model.Permissions {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+
+		permissions := make([]model.Permission, 0, len(codes))
+		for _, code := range codes {
+			permission := model.Permission{Code: code, Description: model.Permissions[code]}
+			if err := tx.Where("code = ?", code).FirstOrCreate(&permission).Error; err != nil {
+				return err
+			}
+			permissions = append(permissions, permission)
+		}
+
+		for name, description := range defaultRoleDescriptions {
+			role := model.Role{Name: name, Description: description}
+			if err := tx.Where("name = ?", name).FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+			if name == model.RoleAdmin {
+				if err := tx.Model(&role).Association("Permissions").Append(permissions); err != nil {
+					return err
+				}
+			}
+		}
+
+		return seedAdminUsers(tx, config.Current.Rbac.AdminUsers)
+	})
+}
+
+// seedAdminUsers 为配置中的已存在用户补充 admin 角色
+func seedAdminUsers(tx *gorm.DB, usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	var adminRole model.Role
+	if err := tx.Where("name = ?", model.RoleAdmin).First(&adminRole).Error; err != nil {
+		return err
+	}
+
+	var users []model.User
+	if err := tx.Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		return err
+	}
+	for i := range users {
+		if err := tx.Model(&users[i]).Association("Roles").Append(&adminRole); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
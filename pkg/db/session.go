@@ -0,0 +1,89 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+)
+
+func CreateSession(session *model.Session) error {
+	return DB.Create(session).Error
+}
+
+func GetUserSession(userId uuid.UUID, id string) (model.Session, error) {
+	var session model.Session
+	result := DB.First(&session, "id = ? AND user_id = ?", id, userId)
+	if result.Error != nil {
+		return session, result.Error
+	}
+
+	return session, nil
+}
+
+// ListUserSessions 返回用户未吊销且未过期的会话，最近活跃的在前
+func ListUserSessions(userId uuid.UUID, now time.Time) ([]model.Session, error) {
+	var sessions []model.Session
+	result := DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, now).
+		Order("last_seen_at DESC").
+		Find(&sessions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return sessions, nil
+}
+
+// RotateSession refresh token 轮换后记录新的 access token 并延长会话，返回 false 表示会话不存在或已吊销
+func RotateSession(id uuid.UUID, jti string, ip string, seenAt time.Time, expiresAt time.Time) (bool, error) {
+	result := DB.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"jti": jti, "ip": ip, "last_seen_at": seenAt, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func TouchSession(id string, ip string, seenAt time.Time) error {
+	return DB.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"ip": ip, "last_seen_at": seenAt}).Error
+}
+
+// RevokeSession 吊销会话，返回 false 表示会话已被吊销
+func RevokeSession(id uuid.UUID, revokedAt time.Time) (bool, error) {
+	result := DB.Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeUserSessions 吊销用户的全部会话
+func RevokeUserSessions(userId uuid.UUID, revokedAt time.Time) error {
+	return DB.Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", revokedAt).Error
+}
+
+// IsSessionRevoked 会话已吊销或不存在时返回 true
+func IsSessionRevoked(id string) (bool, error) {
+	var count int64
+	result := DB.Model(&model.Session{}).Where("id = ? AND revoked_at IS NULL", id).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return count == 0, nil
+}
+
+// DeleteExpiredSessions 清理已过期的会话记录
+func DeleteExpiredSessions(before time.Time) error {
+	return DB.Where("expires_at < ?", before).Delete(&model.Session{}).Error
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"go-fiber-starter/pkg/tenant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrTenantRequired = errors.New("tenant required for tenant-scoped model")
+	ErrTenantMismatch = errors.New("record belongs to another tenant")
+	ErrTenantUpsert   = errors.New("upsert is not allowed on tenant-scoped model")
+)
+
+// Tenant 返回携带 ctx 的连接，ctx 中的组织决定多租户模型的可见范围
+func Tenant(ctx context.Context) *gorm.DB {
+	return DB.WithContext(ctx)
+}
+
+// AllTenants 返回可以跨租户读写的连接，只应用于管理后台、后台任务等确需访问全部组织数据的场景
+func AllTenants(ctx context.Context) *gorm.DB {
+	return DB.WithContext(tenant.WithCrossTenant(ctx))
+}
+
+// RegisterTenantScope 为多租户模型注册自动限定组织的回调：
+// 查询、更新、删除附加 organization_id 条件，创建时写入当前组织；
+// context 中没有组织且未显式允许跨租户时直接返回 ErrTenantRequired。
+// 原生 SQL（Raw/Exec）不经过模型解析，不会被限定。
+func RegisterTenantScope(database *gorm.DB) error {
+	callbacks := database.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenantQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenantQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", scopeTenantWrite); err != nil {
+		return err
+	}
+	return callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenantWrite)
+}
+
+func tenantScoped(stmt *gorm.Statement) bool {
+	if stmt.Schema == nil || stmt.SQL.Len() > 0 {
+		return false
+	}
+	_, ok := reflect.New(stmt.Schema.ModelType).Interface().(tenant.Scoped)
+	return ok
+}
+
+func scopeTenantQuery(tx *gorm.DB) {
+	if tx.Error != nil || !tenantScoped(tx.Statement) {
+		return
+	}
+	scopeTenant(tx)
+}
+
+// scopeTenantWrite 没有条件也没有主键的批量更新/删除保持原样，交给 gorm 按 ErrMissingWhereClause 拒绝，
+// 避免补上组织条件后变成整个组织范围内的批量操作
+func scopeTenantWrite(tx *gorm.DB) {
+	stmt := tx.Statement
+	if tx.Error != nil || !tenantScoped(stmt) {
+		return
+	}
+	if _, ok := stmt.Clauses["WHERE"]; !ok && !tx.AllowGlobalUpdate && !hasPrimaryKeyValues(stmt) {
+		return
+	}
+	scopeTenant(tx)
+}
+
+func scopeTenant(tx *gorm.DB) {
+	stmt := tx.Statement
+	if tenant.IsCrossTenant(stmt.Context) {
+		return
+	}
+	organizationId, ok := tenant.FromContext(stmt.Context)
+	if !ok {
+		_ = tx.AddError(ErrTenantRequired)
+		return
+	}
+
+	// 已有条件整体加括号后再与组织条件 AND，避免 OR 条件绕过组织限制
+	where := clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: tenant.Column}, Value: organizationId},
+	}}
+	if existing, ok := stmt.Clauses["WHERE"]; ok {
+		if current, ok := existing.Expression.(clause.Where); ok && len(current.Exprs) > 0 {
+			where.Exprs = append(where.Exprs, clause.And(current.Exprs...))
+		}
+		existing.Expression = where
+		stmt.Clauses["WHERE"] = existing
+		return
+	}
+	stmt.AddClause(where)
+}
+
+func hasPrimaryKeyValues(stmt *gorm.Statement) bool {
+	if _, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields); len(values) > 0 {
+		return true
+	}
+	if stmt.Model == nil {
+		return false
+	}
+	_, values := schema.GetIdentityFieldValuesMap(stmt.Context, reflect.ValueOf(stmt.Model), stmt.Schema.PrimaryFields)
+	return len(values) > 0
+}
+
+// assignTenant 创建时补全组织 Id；记录已指定其他组织时拒绝写入，跨租户模式下要求显式指定组织
+func assignTenant(tx *gorm.DB) {
+	stmt := tx.Statement
+	if tx.Error != nil || !tenantScoped(stmt) {
+		return
+	}
+
+	crossTenant := tenant.IsCrossTenant(stmt.Context)
+	if _, ok := stmt.Clauses["ON CONFLICT"]; ok && !crossTenant {
+		// upsert 冲突时会直接覆盖已存在的记录，无法保证该记录属于当前组织
+		_ = tx.AddError(ErrTenantUpsert)
+		return
+	}
+	organizationId, hasTenant := tenant.FromContext(stmt.Context)
+	if !hasTenant && !crossTenant {
+		_ = tx.AddError(ErrTenantRequired)
+		return
+	}
+	field := stmt.Schema.LookUpField("OrganizationId")
+	if field == nil {
+		_ = tx.AddError(ErrTenantRequired)
+		return
+	}
+
+	assign := func(value reflect.Value) error {
+		current, zero := field.ValueOf(stmt.Context, value)
+		if zero {
+			if !hasTenant {
+				return ErrTenantRequired
+			}
+			return field.Set(stmt.Context, value, organizationId)
+		}
+		if hasTenant && !crossTenant && current.(uuid.UUID) != organizationId {
+			return ErrTenantMismatch
+		}
+		return nil
+	}
+
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			if err := assign(reflect.Indirect(stmt.ReflectValue.Index(i))); err != nil {
+				_ = tx.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := assign(stmt.ReflectValue); err != nil {
+			_ = tx.AddError(err)
+		}
+	default:
+		// map 等无法逐条检查组织的写入方式只在跨租户模式下允许
+		if !crossTenant {
+			_ = tx.AddError(ErrTenantRequired)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-fiber-starter/internal/model/base"
+	"go-fiber-starter/pkg/tenant"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type tenantNote struct {
+	base.TenantModel
+	Title string
+}
+
+func setupTenantDB(t *testing.T) {
+	t.Helper()
+
+	gormDB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := RegisterTenantScope(gormDB); err != nil {
+		t.Fatalf("register tenant scope: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&tenantNote{}); err != nil {
+		t.Fatalf("auto migrate: %v", err)
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		t.Fatalf("get sql db: %v", err)
+	}
+
+	prevDB := DB
+	DB = gormDB
+	t.Cleanup(func() {
+		_ = sqlDB.Close()
+		DB = prevDB
+	})
+}
+
+func TestTenantScopeIsolatesRows(t *testing.T) {
+	setupTenantDB(t)
+	orgA, orgB := uuid.New(), uuid.New()
+	ctxA := tenant.WithTenant(context.Background(), orgA)
+	ctxB := tenant.WithTenant(context.Background(), orgB)
+
+	if err := DB.Create(&tenantNote{Title: "orphan"}).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected create without tenant to fail, got %v", err)
+	}
+
+	noteA := tenantNote{Title: "a"}
+	if err := Tenant(ctxA).Create(&noteA).Error; err != nil {
+		t.Fatalf("create note a: %v", err)
+	}
+	if noteA.OrganizationId != orgA {
+		t.Fatalf("organization id %s != %s", noteA.OrganizationId, orgA)
+	}
+	noteB := tenantNote{Title: "b"}
+	if err := Tenant(ctxB).Create(&noteB).Error; err != nil {
+		t.Fatalf("create note b: %v", err)
+	}
+
+	var notes []tenantNote
+	if err := Tenant(ctxA).Where("title = ?", "a").Or("title = ?", "b").Find(&notes).Error; err != nil {
+		t.Fatalf("query notes: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Id != noteA.Id {
+		t.Fatalf("expected only tenant a's note, got %+v", notes)
+	}
+
+	var count int64
+	if err := DB.Model(&tenantNote{}).Count(&count).Error; !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected query without tenant to fail, got %v", err)
+	}
+	if err := AllTenants(context.Background()).Model(&tenantNote{}).Count(&count).Error; err != nil || count != 2 {
+		t.Fatalf("expected cross tenant count 2, got %d (%v)", count, err)
+	}
+
+	var other tenantNote
+	if err := Tenant(ctxA).First(&other, "id = ?", noteB.Id).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("expected other tenant's note to be invisible, got %v", err)
+	}
+}
+
+func TestTenantScopeGuardsWrites(t *testing.T) {
+	setupTenantDB(t)
+	orgA, orgB := uuid.New(), uuid.New()
+	ctxA := tenant.WithTenant(context.Background(), orgA)
+	ctxB := tenant.WithTenant(context.Background(), orgB)
+
+	noteB := tenantNote{Title: "b"}
+	if err := Tenant(ctxB).Create(&noteB).Error; err != nil {
+		t.Fatalf("create note b: %v", err)
+	}
+
+	result := Tenant(ctxA).Model(&tenantNote{}).Where("id = ?", noteB.Id).Update("title", "hijacked")
+	if result.Error != nil || result.RowsAffected != 0 {
+		t.Fatalf("expected cross tenant update to affect nothing, got %d (%v)", result.RowsAffected, result.Error)
+	}
+	result = Tenant(ctxA).Delete(&tenantNote{}, "id = ?", noteB.Id)
+	if result.Error != nil || result.RowsAffected != 0 {
+		t.Fatalf("expected cross tenant delete to affect nothing, got %d (%v)", result.RowsAffected, result.Error)
+	}
+	if err := Tenant(ctxA).Delete(&tenantNote{}).Error; !errors.Is(err, gorm.ErrMissingWhereClause) {
+		t.Fatalf("expected unconditional delete to be rejected, got %v", err)
+	}
+
+	foreign := tenantNote{Title: "foreign"}
+	foreign.OrganizationId = orgB
+	if err := Tenant(ctxA).Create(&foreign).Error; !errors.Is(err, ErrTenantMismatch) {
+		t.Fatalf("expected create into other tenant to fail, got %v", err)
+	}
+
+	stolen := noteB
+	stolen.OrganizationId = orgA
+	stolen.Title = "stolen"
+	if err := Tenant(ctxA).Save(&stolen).Error; !errors.Is(err, ErrTenantUpsert) {
+		t.Fatalf("expected save over other tenant's row to fail, got %v", err)
+	}
+
+	var reloaded tenantNote
+	if err := Tenant(ctxB).First(&reloaded, "id = ?", noteB.Id).Error; err != nil {
+		t.Fatalf("reload note b: %v", err)
+	}
+	if reloaded.Title != "b" || reloaded.OrganizationId != orgB {
+		t.Fatalf("note b was modified: %+v", reloaded)
+	}
+}
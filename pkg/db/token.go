@@ -0,0 +1,49 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+)
+
+func CreateRefreshToken(token *model.RefreshToken) error {
+	return DB.Create(token).Error
+}
+
+func GetRefreshTokenByHash(hash string) (model.RefreshToken, error) {
+	var token model.RefreshToken
+	result := DB.First(&token, "token_hash = ?", hash)
+	if result.Error != nil {
+		return token, result.Error
+	}
+
+	return token, nil
+}
+
+// MarkRefreshTokenUsed 将 token 标记为已轮换，返回 false 表示 token 已被其他请求抢先使用
+func MarkRefreshTokenUsed(id uuid.UUID, replacedBy uuid.UUID, usedAt time.Time) (bool, error) {
+	result := DB.Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"used_at": usedAt, "replaced_by": replacedBy})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeRefreshTokenFamily 吊销同一 family 下所有尚未吊销的 refresh token
+func RevokeRefreshTokenFamily(familyId uuid.UUID, revokedAt time.Time) error {
+	return DB.Model(&model.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", revokedAt).Error
+}
+
+// RevokeUserRefreshTokens 吊销用户的全部 refresh token
+func RevokeUserRefreshTokens(userId uuid.UUID, revokedAt time.Time) error {
+	return DB.Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", revokedAt).Error
+}
@@ -7,14 +7,97 @@
  */
 package db
 
-import model "go-fiber-starter/internal/model/user"
+import (
+	model "go-fiber-starter/internal/model/user"
+	"go-fiber-starter/pkg/query"
+
+	"gorm.io/gorm"
+)
 
 func GetUserById(id string) (model.User, error) {
 	var user model.User
-	result := DB.First(&user, "id = ?", id)
+	result := DB.Preload("Roles").First(&user, "id = ?", id)
 	if result.Error != nil {
 		return user, result.Error
 	}
 
 	return user, nil
 }
+
+// ListUsers 按用户名模糊搜索、按状态过滤，并按 params 排序、过滤和分页返回用户
+func ListUsers(keyword string, status string, params query.Params) ([]model.User, query.PageInfo, error) {
+	q := DB.Model(&model.User{})
+	if keyword != "" {
+		q = q.Where(query.Contains("username", keyword))
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var users []model.User
+	info, err := query.Find(q, params, &users, preloadRoles)
+	if err != nil {
+		return nil, query.PageInfo{}, err
+	}
+	return users, info, nil
+}
+
+func preloadRoles(q *gorm.DB) *gorm.DB {
+	return q.Preload("Roles")
+}
+
+func UpdateUserFields(user *model.User, fields map[string]interface{}) error {
+	return DB.Model(user).Updates(fields).Error
+}
+
+func ReplaceUserRoles(user *model.User, roles []model.Role) error {
+	return DB.Model(user).Association("Roles").Replace(roles)
+}
+
+// DeleteUser 删除用户及其角色关联、token、邮件 token 和按用户名计数的登录失败记录
+func DeleteUser(user *model.User) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.Identity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.ApiKey{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.Session{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.Membership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.Id).Delete(&model.UserToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("attempt_key = ?", LoginAttemptAccountKey(user.Username)).Delete(&model.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(user).Error
+	})
+}
+
+// EmailTaken 判断邮箱是否已被其他用户使用
+func EmailTaken(email string, excludeId string) (bool, error) {
+	var count int64
+	query := DB.Model(&model.User{}).Where("LOWER(email) = LOWER(?)", email)
+	if excludeId != "" {
+		query = query.Where("id <> ?", excludeId)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
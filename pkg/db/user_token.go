@@ -0,0 +1,54 @@
+package db
+
+import (
+	"time"
+
+	model "go-fiber-starter/internal/model/user"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CreateUserToken 创建一次性 token，同时作废该用户同用途的旧 token
+func CreateUserToken(token *model.UserToken) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserId, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func GetUserTokenByHash(purpose string, hash string) (model.UserToken, error) {
+	var token model.UserToken
+	result := DB.First(&token, "purpose = ? AND token_hash = ?", purpose, hash)
+	if result.Error != nil {
+		return token, result.Error
+	}
+
+	return token, nil
+}
+
+// ConsumeUserToken 将 token 标记为已使用，返回 false 表示已被使用过
+func ConsumeUserToken(id uuid.UUID, usedAt time.Time) (bool, error) {
+	result := DB.Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func GetUserByEmail(email string) (model.User, error) {
+	var user model.User
+	result := DB.First(&user, "LOWER(email) = LOWER(?)", email)
+	if result.Error != nil {
+		return user, result.Error
+	}
+
+	return user, nil
+}
@@ -0,0 +1,265 @@
+// Package i18n 接口提示的多语言支持：按 key 从语言包中取出对应语言的文本，支持 {name} 占位符和按 count 选择复数形式。
+//
+// 语言包为 YAML 文件，文件名即语言标识（例如 en.yaml、zh-CN.yaml），内置语言包位于 locales 目录，
+// 可以通过 i18n.dir 配置额外的目录覆盖或补充。每个 key 的值可以是字符串，也可以是按复数类别（zero/one/other）区分的映射：
+//
+//	auth.invalid_credentials: "Invalid username or password"
+//	auth.too_many_attempts:
+//	  one: "Too many failed attempts, try again in {count} second"
+//	  other: "Too many failed attempts, try again in {count} seconds"
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-fiber-starter/pkg/config"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var localeFS embed.FS
+
+// Params 占位符参数，count 同时用于选择复数形式
+type Params map[string]interface{}
+
+// message 一条文本的各个复数形式，普通字符串只有 other
+type message map[string]string
+
+// Catalog 全部语言包，创建后只读，可以并发使用
+type Catalog struct {
+	defaultLocale string
+	// locales 受支持的语言，默认语言排在第一位
+	locales []string
+	matcher language.Matcher
+	bundles map[string]map[string]message
+}
+
+// Current 未调用 Init 时只包含内置语言包，默认语言为 zh-CN
+var Current = mustLoadBuiltin()
+
+func mustLoadBuiltin() *Catalog {
+	catalog, err := Load(config.I18nConfig{}.Default(), "")
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+}
+
+// Init 按配置加载语言包
+func Init() error {
+	catalog, err := Load(config.Current.I18n.Default(), config.Current.I18n.Dir)
+	if err != nil {
+		return err
+	}
+	Current = catalog
+	return nil
+}
+
+// Load 加载内置语言包，dir 非空时再加载其中的 *.yaml，同名 key 覆盖内置文本
+func Load(defaultLocale string, dir string) (*Catalog, error) {
+	bundles := make(map[string]map[string]message)
+	if err := loadDir(bundles, localeFS, "locales"); err != nil {
+		return nil, err
+	}
+	if dir != "" {
+		if err := loadDir(bundles, os.DirFS(dir), "."); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := bundles[defaultLocale]; !ok {
+		return nil, fmt.Errorf("默认语言 %s 没有对应的语言包", defaultLocale)
+	}
+
+	catalog := &Catalog{defaultLocale: defaultLocale, bundles: bundles, locales: []string{defaultLocale}}
+	for locale := range bundles {
+		if locale != defaultLocale {
+			catalog.locales = append(catalog.locales, locale)
+		}
+	}
+	tags := make([]language.Tag, len(catalog.locales))
+	for i, locale := range catalog.locales {
+		tags[i] = language.Make(locale)
+	}
+	catalog.matcher = language.NewMatcher(tags)
+	return catalog, nil
+}
+
+func loadDir(bundles map[string]map[string]message, fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, filepath.ToSlash(filepath.Join(dir, "*.yaml")))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		locale := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		if _, err := language.Parse(locale); err != nil {
+			return fmt.Errorf("语言包 %s 的文件名不是有效的语言标识: %w", path, err)
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		var raw map[string]interface{}
+		if err := yaml.Unmarshal(content, &raw); err != nil {
+			return fmt.Errorf("解析语言包 %s 失败: %w", path, err)
+		}
+
+		bundle, ok := bundles[locale]
+		if !ok {
+			bundle = make(map[string]message)
+			bundles[locale] = bundle
+		}
+		for key, value := range raw {
+			parsed, err := parseMessage(value)
+			if err != nil {
+				return fmt.Errorf("语言包 %s 中的 %s: %w", path, key, err)
+			}
+			bundle[key] = parsed
+		}
+	}
+	return nil
+}
+
+func parseMessage(value interface{}) (message, error) {
+	switch typed := value.(type) {
+	case string:
+		return message{"other": typed}, nil
+	case map[string]interface{}:
+		forms := make(message, len(typed))
+		for form, text := range typed {
+			str, ok := text.(string)
+			if !ok || (form != "zero" && form != "one" && form != "other") {
+				return nil, fmt.Errorf("复数形式只能是 zero/one/other 对应的字符串")
+			}
+			forms[form] = str
+		}
+		if forms["other"] == "" {
+			return nil, fmt.Errorf("缺少 other 形式")
+		}
+		return forms, nil
+	default:
+		return nil, fmt.Errorf("值必须是字符串或复数形式映射")
+	}
+}
+
+// Default 默认语言
+func (c *Catalog) Default() string {
+	return c.defaultLocale
+}
+
+// Locales 受支持的语言，默认语言排在第一位
+func (c *Catalog) Locales() []string {
+	return append([]string(nil), c.locales...)
+}
+
+// Match 按偏好选择受支持的语言，preference 可以是单个语言标识或 Accept-Language 头；没有可用的语言时返回 false
+func (c *Catalog) Match(preference string) (string, bool) {
+	if strings.TrimSpace(preference) == "" {
+		return "", false
+	}
+	tags, _, err := language.ParseAcceptLanguage(strings.ReplaceAll(preference, "_", "-"))
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return c.locales[index], true
+}
+
+// Has key 是否存在于默认语言包中
+func (c *Catalog) Has(key string) bool {
+	_, ok := c.bundles[c.defaultLocale][key]
+	return ok
+}
+
+// T 返回 key 在 locale 下的文本，缺失时依次回退到默认语言和 key 本身，因此未收录的原文也会原样返回（并替换占位符）
+func (c *Catalog) T(locale string, key string, params ...Params) string {
+	msg, ok := c.bundles[locale][key]
+	if !ok {
+		locale = c.defaultLocale
+		msg, ok = c.bundles[locale][key]
+	}
+	if !ok {
+		msg = message{"other": key}
+	}
+
+	var merged Params
+	if len(params) > 0 {
+		merged = make(Params)
+		for _, p := range params {
+			for name, value := range p {
+				merged[name] = value
+			}
+		}
+	}
+
+	text := msg["other"]
+	if count, ok := toFloat(merged["count"]); ok {
+		if form, ok := msg[pluralForm(locale, count)]; ok {
+			text = form
+		}
+	}
+	return replacePlaceholders(text, merged)
+}
+
+// pluralForm 常用语言的复数类别，中日韩语没有复数变化；未收录的语言按英语规则处理
+func pluralForm(locale string, count float64) string {
+	if count == 0 {
+		return "zero"
+	}
+	base, _ := language.Make(locale).Base()
+	switch base.String() {
+	case "zh", "ja", "ko":
+		return "other"
+	case "fr":
+		if count < 2 {
+			return "one"
+		}
+	default:
+		if count == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+func replacePlaceholders(text string, params Params) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case int:
+		return float64(typed), true
+	case int64:
+		return float64(typed), true
+	case float64:
+		return typed, true
+	case string:
+		parsed, err := strconv.ParseFloat(typed, 64)
+		return parsed, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// T 使用 Current 翻译
+func T(locale string, key string, params ...Params) string {
+	return Current.T(locale, key, params...)
+}
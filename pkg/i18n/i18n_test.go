@@ -0,0 +1,107 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTranslatePlaceholdersAndPlurals(t *testing.T) {
+	catalog, err := Load("zh-CN", "")
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+
+	cases := []struct {
+		locale string
+		key    string
+		params Params
+		want   string
+	}{
+		{"en", "auth.too_many_attempts", Params{"count": 1}, "Too many failed login attempts, please try again in 1 second"},
+		{"en", "auth.too_many_attempts", Params{"count": 30}, "Too many failed login attempts, please try again in 30 seconds"},
+		{"zh-CN", "auth.too_many_attempts", Params{"count": 1}, "登录失败次数过多，请1秒后再试"},
+		{"en", "validation.required", Params{"field": "Username"}, "Username is required"},
+		// 缺失的语言回退到默认语言，未收录的 key 原样返回
+		{"fr", "auth.invalid_credentials", nil, "用户名或密码错误"},
+		{"en", "未收录的{name}", Params{"name": "文本"}, "未收录的文本"},
+	}
+	for _, tc := range cases {
+		if got := catalog.T(tc.locale, tc.key, tc.params); got != tc.want {
+			t.Fatalf("T(%s, %s) = %q, want %q", tc.locale, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	catalog, err := Load("zh-CN", "")
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+
+	cases := map[string]string{
+		"en-US,en;q=0.9": "en",
+		"en_GB":          "en",
+		"zh-TW":          "zh-CN",
+		"fr-FR,en;q=0.5": "en",
+	}
+	for preference, want := range cases {
+		if got, ok := catalog.Match(preference); !ok || got != want {
+			t.Fatalf("Match(%q) = %q, %v, want %q", preference, got, ok, want)
+		}
+	}
+	if _, ok := catalog.Match("fr-FR"); ok {
+		t.Fatalf("expected unsupported language not to match")
+	}
+}
+
+func TestLoadDirOverridesBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "en.yaml"), "auth.invalid_credentials: \"Wrong credentials\"\n")
+	writeFile(t, filepath.Join(dir, "ja.yaml"), "auth.invalid_credentials: \"ユーザー名またはパスワードが違います\"\n")
+
+	catalog, err := Load("en", dir)
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	if got := catalog.T("en", "auth.invalid_credentials"); got != "Wrong credentials" {
+		t.Fatalf("expected override, got %q", got)
+	}
+	if got := catalog.T("en", "auth.account_disabled"); got != "Your account has been disabled" {
+		t.Fatalf("expected builtin text to be kept, got %q", got)
+	}
+	if got, ok := catalog.Match("ja-JP"); !ok || got != "ja" {
+		t.Fatalf("expected added locale to be supported, got %q", got)
+	}
+	if got, _ := catalog.Match("de"); got != "" {
+		t.Fatalf("unexpected match %q", got)
+	}
+
+	if _, err := Load("de", dir); err == nil {
+		t.Fatalf("expected missing default locale to fail")
+	}
+}
+
+// 内置语言包的 key 必须一一对应，避免某种语言只能回退到默认语言
+func TestBuiltinCatalogsHaveSameKeys(t *testing.T) {
+	catalog, err := Load("zh-CN", "")
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	for _, locale := range catalog.Locales() {
+		for _, other := range catalog.Locales() {
+			for key := range catalog.bundles[locale] {
+				if _, ok := catalog.bundles[other][key]; !ok {
+					t.Errorf("%s is missing key %s from %s", other, key, locale)
+				}
+			}
+		}
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,185 @@
+package jwtkey
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-fiber-starter/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKid   = errors.New("unknown jwt kid")
+	ErrAlgMismatch  = errors.New("jwt alg does not match key")
+	ErrNoSigningKey = errors.New("no jwt signing key")
+)
+
+// Key 单个签名/验签密钥
+type Key struct {
+	Kid        string
+	Method     jwt.SigningMethod
+	PrivateKey interface{} // 仅签名密钥需要
+	PublicKey  interface{}
+}
+
+// KeySet 当前进程可用的全部密钥，签名使用 signing，验签按 kid 选择
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	ordered []*Key
+}
+
+var Current *KeySet
+
+func Init() error {
+	keySet, err := Load(config.Current.Jwt)
+	if err != nil {
+		return err
+	}
+
+	Current = keySet
+	return nil
+}
+
+// Active 返回已初始化的密钥集；未初始化时按当前配置的 secret 构造 HS256 密钥集
+func Active() *KeySet {
+	if Current != nil {
+		return Current
+	}
+	return secretKeySet(config.Current.Jwt.Secret)
+}
+
+// Load 根据配置加载密钥集，未配置 keys 时使用 HS256 + secret
+func Load(jwtConfig config.JwtConfig) (*KeySet, error) {
+	if len(jwtConfig.Keys) == 0 {
+		if strings.TrimSpace(jwtConfig.Secret) == "" {
+			return nil, errors.New("jwt secret 与 keys 不能同时为空")
+		}
+		return secretKeySet(jwtConfig.Secret), nil
+	}
+
+	keySet := &KeySet{keys: make(map[string]*Key, len(jwtConfig.Keys))}
+	for _, keyConfig := range jwtConfig.Keys {
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := keySet.keys[key.Kid]; exists {
+			return nil, fmt.Errorf("jwt kid 重复: %s", key.Kid)
+		}
+		keySet.keys[key.Kid] = key
+		keySet.ordered = append(keySet.ordered, key)
+	}
+
+	signingKid := strings.TrimSpace(jwtConfig.SigningKid)
+	if signingKid != "" {
+		key, ok := keySet.keys[signingKid]
+		if !ok {
+			return nil, fmt.Errorf("jwt signingKid %s 不存在", signingKid)
+		}
+		if key.PrivateKey == nil {
+			return nil, fmt.Errorf("jwt signingKid %s 未配置私钥", signingKid)
+		}
+		keySet.signing = key
+	} else {
+		for _, key := range keySet.ordered {
+			if key.PrivateKey != nil {
+				keySet.signing = key
+				break
+			}
+		}
+	}
+	if keySet.signing == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return keySet, nil
+}
+
+func secretKeySet(secret string) *KeySet {
+	key := &Key{
+		Method:     jwt.SigningMethodHS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}
+	return &KeySet{signing: key, keys: map[string]*Key{"": key}}
+}
+
+func loadKey(keyConfig config.JwtKeyConfig) (*Key, error) {
+	kid := strings.TrimSpace(keyConfig.Kid)
+	if kid == "" {
+		return nil, errors.New("jwt key 的 kid 不能为空")
+	}
+
+	method, err := signingMethod(keyConfig.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+	}
+
+	key := &Key{Kid: kid, Method: method}
+	if keyConfig.PrivateKeyFile != "" {
+		privateKey, err := readPrivateKey(keyConfig.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		key.PrivateKey = privateKey
+		key.PublicKey = publicKeyOf(privateKey)
+	}
+	if keyConfig.PublicKeyFile != "" {
+		publicKey, err := readPublicKey(keyConfig.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		key.PublicKey = publicKey
+	}
+	if key.PublicKey == nil {
+		return nil, fmt.Errorf("jwt key %s 未配置 privateKeyFile 或 publicKeyFile", kid)
+	}
+	if err := checkKeyType(method, key.PublicKey); err != nil {
+		return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+	}
+
+	return key, nil
+}
+
+func signingMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch strings.ToUpper(strings.TrimSpace(algorithm)) {
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "ES256":
+		return jwt.SigningMethodES256, nil
+	case "EDDSA", "ED25519":
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", algorithm)
+	}
+}
+
+// SigningKid 返回当前签名密钥的 kid，HS256 模式下为空
+func (ks *KeySet) SigningKid() string {
+	return ks.signing.Kid
+}
+
+// Sign 使用当前签名密钥签发 token，非对称密钥会写入 kid 头
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	if ks.signing.Kid != "" {
+		token.Header["kid"] = ks.signing.Kid
+	}
+	return token.SignedString(ks.signing.PrivateKey)
+}
+
+// Keyfunc 按 token 头中的 kid 选择验签密钥，并校验 alg 与密钥一致
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKid
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrAlgMismatch
+	}
+	return key.PublicKey, nil
+}
@@ -0,0 +1,265 @@
+package jwtkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-fiber-starter/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLoadFallsBackToSecret(t *testing.T) {
+	t.Parallel()
+
+	keySet, err := Load(config.JwtConfig{Secret: "test-secret"})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	tokenString, err := keySet.Sign(jwt.MapClaims{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+	if _, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	}); err != nil {
+		t.Fatalf("token not signed with secret: %v", err)
+	}
+	if len(keySet.JWKS().Keys) != 0 {
+		t.Fatalf("HS256 key set must not publish keys")
+	}
+}
+
+func TestLoadAsymmetricKeys(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	cases := []struct {
+		algorithm string
+		kty       string
+		key       interface{}
+	}{
+		{algorithm: "RS256", kty: "RSA", key: mustRSAKey(t)},
+		{algorithm: "ES256", kty: "EC", key: mustECKey(t)},
+		{algorithm: "EdDSA", kty: "OKP", key: mustEdKey(t)},
+	}
+
+	for _, tc := range cases {
+		keyPath := writePrivateKey(t, dir, tc.algorithm, tc.key)
+		keySet, err := Load(config.JwtConfig{
+			Keys: []config.JwtKeyConfig{{Kid: tc.algorithm, Algorithm: tc.algorithm, PrivateKeyFile: keyPath}},
+		})
+		if err != nil {
+			t.Fatalf("%s: Load returned error: %v", tc.algorithm, err)
+		}
+
+		tokenString, err := keySet.Sign(jwt.MapClaims{"exp": time.Now().Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatalf("%s: Sign returned error: %v", tc.algorithm, err)
+		}
+		parsed, err := jwt.Parse(tokenString, keySet.Keyfunc)
+		if err != nil || !parsed.Valid {
+			t.Fatalf("%s: verify failed: %v", tc.algorithm, err)
+		}
+		if parsed.Header["kid"] != tc.algorithm {
+			t.Fatalf("%s: kid header %v", tc.algorithm, parsed.Header["kid"])
+		}
+
+		jwks := keySet.JWKS()
+		if len(jwks.Keys) != 1 || jwks.Keys[0].Kty != tc.kty || jwks.Keys[0].Kid != tc.algorithm {
+			t.Fatalf("%s: unexpected jwks %+v", tc.algorithm, jwks)
+		}
+	}
+}
+
+func TestRotationKeepsOldKeyVerifiable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	oldKeyPath := writePrivateKey(t, dir, "old", mustRSAKey(t))
+	newKeyPath := writePrivateKey(t, dir, "new", mustRSAKey(t))
+	oldPublicPath := writePublicKey(t, dir, "old", readPrivate(t, oldKeyPath))
+
+	before, err := Load(config.JwtConfig{
+		Keys: []config.JwtKeyConfig{{Kid: "old", Algorithm: "RS256", PrivateKeyFile: oldKeyPath}},
+	})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	oldToken, err := before.Sign(jwt.MapClaims{"sub": "alice"})
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	// 轮换后旧密钥只保留公钥，签名切换到新密钥
+	after, err := Load(config.JwtConfig{
+		SigningKid: "new",
+		Keys: []config.JwtKeyConfig{
+			{Kid: "old", Algorithm: "RS256", PublicKeyFile: oldPublicPath},
+			{Kid: "new", Algorithm: "RS256", PrivateKeyFile: newKeyPath},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if after.SigningKid() != "new" {
+		t.Fatalf("signing kid %s != new", after.SigningKid())
+	}
+	if _, err := jwt.Parse(oldToken, after.Keyfunc); err != nil {
+		t.Fatalf("old token should still verify: %v", err)
+	}
+	if len(after.JWKS().Keys) != 2 {
+		t.Fatalf("expected both keys in jwks")
+	}
+}
+
+func TestKeyfuncRejectsUnknownKidAndAlg(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	keySet, err := Load(config.JwtConfig{
+		Keys: []config.JwtKeyConfig{{Kid: "k1", Algorithm: "RS256", PrivateKeyFile: writePrivateKey(t, dir, "k1", mustRSAKey(t))}},
+	})
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{})
+	unknown.Header["kid"] = "k2"
+	signed, _ := unknown.SignedString([]byte("secret"))
+	if _, err := jwt.Parse(signed, keySet.Keyfunc); !errors.Is(err, ErrUnknownKid) {
+		t.Fatalf("expected ErrUnknownKid, got %v", err)
+	}
+
+	// 防止用公钥作为 HMAC 密钥伪造 token
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{})
+	confused.Header["kid"] = "k1"
+	signed, _ = confused.SignedString([]byte("secret"))
+	if _, err := jwt.Parse(signed, keySet.Keyfunc); !errors.Is(err, ErrAlgMismatch) {
+		t.Fatalf("expected ErrAlgMismatch, got %v", err)
+	}
+}
+
+func TestLoadRejectsMismatchedKeyType(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	_, err := Load(config.JwtConfig{
+		Keys: []config.JwtKeyConfig{{Kid: "k1", Algorithm: "ES256", PrivateKeyFile: writePrivateKey(t, dir, "k1", mustRSAKey(t))}},
+	})
+	if err == nil {
+		t.Fatal("Load expected error, got nil")
+	}
+}
+
+func mustRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	return key
+}
+
+func mustECKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+	return key
+}
+
+func mustEdKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519 key: %v", err)
+	}
+	return key
+}
+
+func writePrivateKey(t *testing.T, dir string, name string, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal private key: %v", err)
+	}
+	return writePEM(t, dir, name+".key.pem", "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir string, name string, key *rsa.PrivateKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	return writePEM(t, dir, name+".pub.pem", "PUBLIC KEY", der)
+}
+
+func readPrivate(t *testing.T, path string) *rsa.PrivateKey {
+	t.Helper()
+	signer, err := readPrivateKey(path)
+	if err != nil {
+		t.Fatalf("read private key: %v", err)
+	}
+	return signer.(*rsa.PrivateKey)
+}
+
+func writePEM(t *testing.T, dir string, fileName string, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, fileName)
+	content := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("write pem: %v", err)
+	}
+	return path
+}
+
+func TestJWKPublicKeyRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for algorithm, key := range map[string]interface{}{
+		"RS256": mustRSAKey(t),
+		"ES256": mustECKey(t),
+		"EdDSA": mustEdKey(t),
+	} {
+		keyPath := writePrivateKey(t, dir, algorithm, key)
+		keySet, err := Load(config.JwtConfig{
+			Keys: []config.JwtKeyConfig{{Kid: algorithm, Algorithm: algorithm, PrivateKeyFile: keyPath}},
+		})
+		if err != nil {
+			t.Fatalf("%s: Load returned error: %v", algorithm, err)
+		}
+		tokenString, err := keySet.Sign(jwt.MapClaims{"sub": "alice"})
+		if err != nil {
+			t.Fatalf("%s: Sign returned error: %v", algorithm, err)
+		}
+
+		publicKey, err := keySet.JWKS().Keys[0].PublicKey()
+		if err != nil {
+			t.Fatalf("%s: PublicKey returned error: %v", algorithm, err)
+		}
+		if _, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		}); err != nil {
+			t.Fatalf("%s: token not verifiable with parsed jwk: %v", algorithm, err)
+		}
+	}
+
+	if _, err := (JWK{Kty: "oct"}).PublicKey(); err == nil {
+		t.Fatalf("expected symmetric jwk to be rejected")
+	}
+}
@@ -0,0 +1,99 @@
+package jwtkey
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func readPEM(path string) (*pem.Block, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败 %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("密钥文件不是有效的 PEM 格式: %s", path)
+	}
+	return block, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("不支持的私钥类型: %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("不支持的私钥 PEM 类型: %s", block.Type)
+	}
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("不支持的公钥 PEM 类型: %s", block.Type)
+	}
+}
+
+func publicKeyOf(privateKey crypto.Signer) crypto.PublicKey {
+	publicKey := privateKey.Public()
+	// ed25519.PrivateKey.Public 返回值类型，jwt 库验签需要同样的值类型
+	if edKey, ok := publicKey.(ed25519.PublicKey); ok {
+		return edKey
+	}
+	return publicKey
+}
+
+func checkKeyType(method jwt.SigningMethod, publicKey crypto.PublicKey) error {
+	switch method {
+	case jwt.SigningMethodRS256:
+		if _, ok := publicKey.(*rsa.PublicKey); !ok {
+			return errors.New("RS256 需要 RSA 密钥")
+		}
+	case jwt.SigningMethodES256:
+		ecKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve != elliptic.P256() {
+			return errors.New("ES256 需要 P-256 椭圆曲线密钥")
+		}
+	case jwt.SigningMethodEdDSA:
+		if _, ok := publicKey.(ed25519.PublicKey); !ok {
+			return errors.New("EdDSA 需要 Ed25519 密钥")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go-fiber-starter/pkg/util"
+)
+
+// FileMailer 将邮件以 .eml 文件写入目录，便于本地开发时查看
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir string, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+func (m *FileMailer) Send(_ context.Context, msg Message) error {
+	content, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	suffix, err := util.RandomToken(6)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(m.dir, fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405"), suffix))
+	if err := util.EnsureDir(path); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0600)
+}
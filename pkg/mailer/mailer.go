@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/logger"
+)
+
+const (
+	defaultQueueSize = 100
+	sendTimeout      = 30 * time.Second
+)
+
+// Message 一封待发送的邮件，Text 与 HTML 至少提供一个
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer 邮件发送实现
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var Current Mailer
+
+var (
+	queue     chan Message
+	queueOnce sync.Once
+	pending   sync.WaitGroup
+)
+
+func Init() error {
+	mailer, err := New(config.Current.Mail)
+	if err != nil {
+		return err
+	}
+
+	Current = mailer
+	return nil
+}
+
+// New 根据配置创建邮件发送实现
+func New(mailConfig config.MailConfig) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(mailConfig.Driver)) {
+	case "smtp":
+		return NewSMTPMailer(mailConfig), nil
+	case "", "file":
+		dir := mailConfig.Dir
+		if dir == "" {
+			dir = "data/mail"
+		}
+		return NewFileMailer(dir, mailConfig.From), nil
+	case "memory":
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件驱动: %s", mailConfig.Driver)
+	}
+}
+
+// SendAsync 将邮件放入发送队列后立即返回，队列已满时丢弃并记录日志
+func SendAsync(msg Message) {
+	queueOnce.Do(startWorker)
+
+	pending.Add(1)
+	select {
+	case queue <- msg:
+	default:
+		pending.Done()
+		logger.Error("邮件队列已满，丢弃邮件: to=%v subject=%s", msg.To, msg.Subject)
+	}
+}
+
+// Wait 阻塞直到队列中的邮件全部处理完毕，用于测试和优雅退出
+func Wait() {
+	pending.Wait()
+}
+
+func startWorker() {
+	size := config.Current.Mail.QueueSize
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	queue = make(chan Message, size)
+
+	go func() {
+		for msg := range queue {
+			deliver(msg)
+			pending.Done()
+		}
+	}()
+}
+
+func deliver(msg Message) {
+	mailer := Current
+	if mailer == nil {
+		logger.Warn("未配置邮件发送，跳过邮件: to=%v subject=%s", msg.To, msg.Subject)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	if err := mailer.Send(ctx, msg); err != nil {
+		logger.Error("发送邮件失败: to=%v subject=%s err=%v", msg.To, msg.Subject, err)
+	}
+}
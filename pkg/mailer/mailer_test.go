@@ -0,0 +1,75 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	msg, err := Render("verify_email", "alice@example.com", map[string]string{
+		"Subject":  "请验证你的邮箱",
+		"Greeting": "alice，你好：",
+		"Intro":    "请打开以下链接完成邮箱验证，链接24小时内有效：",
+		"Footer":   "如果这不是你的操作，请忽略此邮件。",
+		"Link":     "https://example.com/verify?token=a&b",
+	})
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if msg.Subject != "请验证你的邮箱" {
+		t.Fatalf("unexpected subject %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "https://example.com/verify?token=a&b") {
+		t.Fatalf("text body missing link: %s", msg.Text)
+	}
+	// HTML 模板需要转义链接中的特殊字符
+	if !strings.Contains(msg.HTML, "token=a&amp;b") {
+		t.Fatalf("html body not escaped: %s", msg.HTML)
+	}
+}
+
+func TestFileMailerWritesEML(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir, "Starter <no-reply@example.com>")
+	if err := mailer.Send(context.Background(), Message{To: []string{"bob@example.com"}, Subject: "你好", Text: "hello"}); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected one eml file, got %v (%v)", files, err)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("read eml: %v", err)
+	}
+	for _, expected := range []string{"To: bob@example.com", "Subject: =?UTF-8?q?", "Message-ID: <", "@example.com>", "hello"} {
+		if !strings.Contains(string(content), expected) {
+			t.Fatalf("eml missing %q:\n%s", expected, content)
+		}
+	}
+}
+
+func TestSendAsyncDeliversToCurrent(t *testing.T) {
+	memory := NewMemoryMailer()
+	previous := Current
+	Current = memory
+	t.Cleanup(func() {
+		Current = previous
+	})
+
+	SendAsync(Message{To: []string{"carol@example.com"}, Subject: "async"})
+	Wait()
+
+	messages := memory.Messages()
+	if len(messages) != 1 || messages[0].Subject != "async" {
+		t.Fatalf("unexpected messages: %+v", messages)
+	}
+}
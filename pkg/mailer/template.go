@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// 每个模板文件定义 subject、text、html 三个块
+//
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.tmpl"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.tmpl"))
+)
+
+// Render 渲染模板生成邮件，name 为模板文件名（不含扩展名）
+func Render(name string, to string, data interface{}) (Message, error) {
+	subject, err := executeText(name+".subject", data)
+	if err != nil {
+		return Message{}, err
+	}
+	text, err := executeText(name+".text", data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var html bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Message{}, fmt.Errorf("渲染邮件模板失败 %s: %w", name, err)
+	}
+
+	return Message{
+		To:      []string{to},
+		Subject: strings.TrimSpace(subject),
+		Text:    strings.TrimSpace(text),
+		HTML:    strings.TrimSpace(html.String()),
+	}, nil
+}
+
+func executeText(name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("渲染邮件模板失败 %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
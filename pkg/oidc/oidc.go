@@ -0,0 +1,338 @@
+// Package oidc 实现 OpenID Connect 依赖方：端点发现、授权码 + PKCE 以及 ID Token 校验
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/jwtkey"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// keysRefreshInterval 遇到未知 kid 时重新拉取 JWKS 的最小间隔，防止被伪造 kid 刷爆
+	keysRefreshInterval = time.Minute
+	clockSkew           = time.Minute
+	maxResponseSize     = 1 << 20
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown oidc provider")
+	ErrNonceMismatch   = errors.New("oidc nonce mismatch")
+	ErrUnknownKey      = errors.New("oidc signing key not found")
+)
+
+// allowedMethods ID Token 只接受非对称签名，避免以公钥作为 HMAC 密钥的混淆攻击
+var allowedMethods = []string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Discovery {issuer}/.well-known/openid-configuration 中使用到的字段
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// Token 授权码换取的 token 响应
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IdToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Claims ID Token 中用于识别和关联用户的声明
+type Claims struct {
+	Nonce             string `json:"nonce"`
+	Azp               string `json:"azp"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+	jwt.RegisteredClaims
+}
+
+// Provider 单个身份提供方，端点与公钥在首次使用时发现并缓存
+type Provider struct {
+	config config.OidcProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *Discovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+var Current map[string]*Provider
+
+func Init() error {
+	providers, err := Load(config.Current.Oidc)
+	if err != nil {
+		return err
+	}
+
+	Current = providers
+	return nil
+}
+
+// Load 根据配置创建身份提供方，不会发起网络请求
+func Load(oidcConfig config.OidcConfig) (map[string]*Provider, error) {
+	providers := make(map[string]*Provider, len(oidcConfig.Providers))
+	for _, providerConfig := range oidcConfig.Providers {
+		if providerConfig.Name == "" || providerConfig.Issuer == "" || providerConfig.ClientId == "" || providerConfig.RedirectUrl == "" {
+			return nil, fmt.Errorf("oidc 身份提供方 %q 缺少 name/issuer/clientId/redirectUrl", providerConfig.Name)
+		}
+		if _, ok := providers[providerConfig.Name]; ok {
+			return nil, fmt.Errorf("oidc 身份提供方重复: %s", providerConfig.Name)
+		}
+		providers[providerConfig.Name] = NewProvider(providerConfig, nil)
+	}
+	return providers, nil
+}
+
+// Get 按名称查找已配置的身份提供方
+func Get(name string) (*Provider, error) {
+	provider, ok := Current[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// NewProvider 创建身份提供方，client 为空时使用带超时的默认客户端
+func NewProvider(providerConfig config.OidcProviderConfig, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Provider{config: providerConfig, client: client}
+}
+
+func (p *Provider) Config() config.OidcProviderConfig {
+	return p.config
+}
+
+// Discover 获取并缓存发现文档，issuer 必须与配置一致
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	issuer := strings.TrimRight(p.config.Issuer, "/")
+	var discovery Discovery
+	if err := p.getJSON(ctx, issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("获取 oidc 发现文档失败: %w", err)
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc issuer 不匹配: %s", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JwksUri == "" {
+		return nil, errors.New("oidc 发现文档缺少必要端点")
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// AuthCodeURL 生成跳转到身份提供方的授权地址
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, verifier string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	} else if !slices.Contains(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientId)
+	query.Set("redirect_uri", p.config.RedirectUrl)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", S256Challenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange 使用授权码和 PKCE verifier 换取 token
+func (p *Provider) Exchange(ctx context.Context, code string, verifier string) (*Token, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectUrl)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.config.ClientId)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientId), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc 换取 token 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &oauthErr)
+		return nil, fmt.Errorf("oidc 换取 token 失败: status=%d error=%s %s", resp.StatusCode, oauthErr.Error, oauthErr.ErrorDescription)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("oidc token 响应格式不正确: %w", err)
+	}
+	if token.IdToken == "" {
+		return nil, errors.New("oidc token 响应缺少 id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、issuer、audience、有效期与 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawToken string, nonce string) (*Claims, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &Claims{}
+	_, err = jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(ctx, kid)
+	},
+		jwt.WithValidMethods(allowedMethods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id_token 校验失败: %w", err)
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("oidc id_token 缺少 sub")
+	}
+	// 存在多个 audience 时 azp 必须是当前客户端
+	if len(claims.Audience) > 1 && claims.Azp != p.config.ClientId {
+		return nil, errors.New("oidc id_token azp 不匹配")
+	}
+	if claims.Nonce != nonce {
+		return nil, ErrNonceMismatch
+	}
+	return claims, nil
+}
+
+// publicKey 按 kid 查找公钥，缓存中没有时按间隔限制重新拉取 JWKS，以支持身份提供方轮换密钥
+func (p *Provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if !p.keysFetchedAt.IsZero() && time.Since(p.keysFetchedAt) < keysRefreshInterval {
+		return nil, ErrUnknownKey
+	}
+
+	var set jwtkey.JWKSet
+	if err := p.getJSON(ctx, p.discovery.JwksUri, &set); err != nil {
+		return nil, fmt.Errorf("获取 oidc JWKS 失败: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// 跳过不支持的密钥类型，不影响其他密钥
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookupKey 调用方需持有锁；token 没有 kid 且只有一个密钥时直接使用该密钥
+func (p *Provider) lookupKey(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, endpoint)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out)
+}
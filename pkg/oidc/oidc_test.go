@@ -0,0 +1,143 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *Provider) {
+	t.Helper()
+
+	server := oidctest.NewServer("client", "secret")
+	t.Cleanup(server.Close)
+
+	provider := NewProvider(config.OidcProviderConfig{
+		Name:         "mock",
+		Issuer:       server.URL,
+		ClientId:     "client",
+		ClientSecret: "secret",
+		RedirectUrl:  "http://app.test/callback",
+	}, nil)
+	return server, provider
+}
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	t.Parallel()
+
+	server, provider := newTestProvider(t)
+	server.SetClaims(map[string]interface{}{"sub": "alice", "email": "alice@example.com", "email_verified": true})
+	ctx := context.Background()
+
+	verifier, err := NewVerifier()
+	if err != nil {
+		t.Fatalf("NewVerifier returned error: %v", err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned error: %v", err)
+	}
+	callback, err := server.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize returned error: %v", err)
+	}
+	if callback.Query().Get("state") != "state-1" {
+		t.Fatalf("state not echoed: %s", callback)
+	}
+
+	// 错误的 verifier 无法换取 token
+	if _, err := provider.Exchange(ctx, callback.Query().Get("code"), "wrong-verifier"); err == nil {
+		t.Fatalf("expected exchange with wrong verifier to fail")
+	}
+
+	callback, _ = server.Authorize(authURL)
+	token, err := provider.Exchange(ctx, callback.Query().Get("code"), verifier)
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+	claims, err := provider.VerifyIDToken(ctx, token.IdToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken returned error: %v", err)
+	}
+	if claims.Subject != "alice" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, token.IdToken, "other-nonce"); !errors.Is(err, ErrNonceMismatch) {
+		t.Fatalf("expected nonce mismatch, got %v", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	server, provider := newTestProvider(t)
+	ctx := context.Background()
+	now := time.Now()
+	valid := jwt.MapClaims{
+		"iss":   server.URL,
+		"aud":   "client",
+		"sub":   "alice",
+		"nonce": "n",
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+	}
+	if _, err := provider.VerifyIDToken(ctx, server.SignIDToken(valid), "n"); err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	cases := map[string]func(jwt.MapClaims){
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "other" },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() },
+		"missing sub":    func(c jwt.MapClaims) { delete(c, "sub") },
+		"azp mismatch":   func(c jwt.MapClaims) { c["aud"] = []string{"client", "other"}; c["azp"] = "other" },
+	}
+	for name, mutate := range cases {
+		claims := jwt.MapClaims{}
+		for key, value := range valid {
+			claims[key] = value
+		}
+		mutate(claims)
+		if _, err := provider.VerifyIDToken(ctx, server.SignIDToken(claims), "n"); err == nil {
+			t.Fatalf("%s: expected verification to fail", name)
+		}
+	}
+
+	// HS256 签名的 token 一律拒绝
+	forged, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, valid).SignedString([]byte("secret"))
+	if _, err := provider.VerifyIDToken(ctx, forged, "n"); err == nil {
+		t.Fatalf("expected HS256 token to be rejected")
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	t.Parallel()
+
+	server := oidctest.NewServer("client", "secret")
+	t.Cleanup(server.Close)
+
+	provider := NewProvider(config.OidcProviderConfig{Issuer: server.URL + "/tenant", ClientId: "client"}, nil)
+	if _, err := provider.Discover(context.Background()); err == nil {
+		t.Fatalf("expected discovery to fail for mismatched issuer")
+	}
+}
+
+func TestLoadValidatesProviders(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Load(config.OidcConfig{Providers: []config.OidcProviderConfig{{Name: "a"}}}); err == nil {
+		t.Fatalf("expected incomplete provider to be rejected")
+	}
+
+	provider := config.OidcProviderConfig{Name: "a", Issuer: "https://idp", ClientId: "c", RedirectUrl: "https://app/cb"}
+	if _, err := Load(config.OidcConfig{Providers: []config.OidcProviderConfig{provider, provider}}); err == nil {
+		t.Fatalf("expected duplicate provider to be rejected")
+	}
+}
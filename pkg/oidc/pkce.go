@@ -0,0 +1,22 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"go-fiber-starter/pkg/util"
+)
+
+// verifierBytes 32 字节随机数编码后为 43 个字符，满足 RFC 7636 的长度要求
+const verifierBytes = 32
+
+// NewVerifier 生成 PKCE code verifier
+func NewVerifier() (string, error) {
+	return util.RandomToken(verifierBytes)
+}
+
+// S256Challenge 计算 code_challenge_method=S256 对应的 code challenge
+func S256Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
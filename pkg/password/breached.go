@@ -0,0 +1,127 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	// prefixLength 按 SHA-1 前 5 位十六进制（20 bit）建立索引，与 Have I Been Pwned 的 k-anonymity 前缀一致
+	prefixLength = 5
+	prefixCount  = 1 << (4 * prefixLength)
+	hashLength   = sha1.Size * 2
+)
+
+// BreachedList 离线泄露密码库。文件每行一个大写或小写的 SHA-1 十六进制摘要，可带 ":次数" 后缀，
+// 必须按摘要升序排列（Have I Been Pwned 下载工具生成的 ordered-by-hash 文件即可直接使用）。
+// 打开时只记录每个前缀在文件中的起始偏移，查询时读取对应区间，不会把整个文件载入内存。
+type BreachedList struct {
+	file    *os.File
+	offsets []int64
+}
+
+// OpenBreachedList 打开并索引泄露密码库
+func OpenBreachedList(path string) (*BreachedList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开泄露密码库失败: %w", err)
+	}
+
+	offsets, err := buildIndex(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("索引泄露密码库失败 %s: %w", path, err)
+	}
+	return &BreachedList{file: file, offsets: offsets}, nil
+}
+
+// buildIndex 返回长度为 prefixCount+1 的偏移表，前缀 p 的记录位于 [offsets[p], offsets[p+1])
+func buildIndex(file *os.File) ([]int64, error) {
+	offsets := make([]int64, prefixCount+1)
+	reader := bufio.NewReaderSize(file, 64*1024)
+
+	var offset int64
+	next := 0
+	lineNo := 0
+	for {
+		line, err := reader.ReadSlice('\n')
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("第 %d 行过长", lineNo+1)
+		}
+		if len(line) > 0 {
+			lineNo++
+			if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+				prefix, parseErr := parsePrefix(trimmed)
+				if parseErr != nil {
+					return nil, fmt.Errorf("第 %d 行格式不正确: %w", lineNo, parseErr)
+				}
+				if prefix+1 < next {
+					return nil, fmt.Errorf("第 %d 行未按摘要升序排列", lineNo)
+				}
+				for ; next <= prefix; next++ {
+					offsets[next] = offset
+				}
+			}
+			offset += int64(len(line))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for ; next <= prefixCount; next++ {
+		offsets[next] = offset
+	}
+	return offsets, nil
+}
+
+func parsePrefix(line []byte) (int, error) {
+	if len(line) < hashLength {
+		return 0, errors.New("不是 SHA-1 摘要")
+	}
+	if _, err := hex.DecodeString(string(line[:hashLength])); err != nil {
+		return 0, err
+	}
+	prefix, err := strconv.ParseUint(string(line[:prefixLength]), 16, 32)
+	return int(prefix), err
+}
+
+// Contains 判断密码是否出现在泄露密码库中，可并发调用
+func (b *BreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	digest := hex.EncodeToString(sum[:])
+	prefix, err := strconv.ParseUint(digest[:prefixLength], 16, 32)
+	if err != nil {
+		return false, err
+	}
+
+	start, end := b.offsets[prefix], b.offsets[prefix+1]
+	if start == end {
+		return false, nil
+	}
+	chunk := make([]byte, end-start)
+	if _, err := b.file.ReadAt(chunk, start); err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+
+	for _, line := range bytes.Split(chunk, []byte{'\n'}) {
+		line = bytes.TrimSpace(line)
+		if len(line) >= hashLength && bytes.EqualFold(line[:hashLength], []byte(digest)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (b *BreachedList) Close() error {
+	return b.file.Close()
+}
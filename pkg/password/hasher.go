@@ -0,0 +1,169 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-fiber-starter/pkg/config"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	argon2idPrefix = "$argon2id$"
+	argon2SaltLen  = 16
+	argon2KeyLen   = 32
+)
+
+var ErrUnsupportedAlgorithm = errors.New("unsupported password hash algorithm")
+
+// Hasher 生成新的密码哈希；校验已有哈希见 Verify，可识别所有支持的算法
+type Hasher interface {
+	Hash(password string) (string, error)
+	// NeedsRehash 已有哈希的算法或参数与当前配置不一致时返回 true
+	NeedsRehash(encoded string) bool
+}
+
+// CurrentHasher 未调用 Init 时使用默认参数的 argon2id
+var CurrentHasher Hasher = NewArgon2idHasher(config.PasswordHashConfig{})
+
+// NewHasher 按配置创建哈希实现
+func NewHasher(hashConfig config.PasswordHashConfig) (Hasher, error) {
+	switch hashConfig.AlgorithmName() {
+	case "argon2id":
+		return NewArgon2idHasher(hashConfig), nil
+	case "bcrypt":
+		cost := hashConfig.BcryptWorkFactor()
+		if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost 超出范围: %d", cost)
+		}
+		return BcryptHasher{Cost: cost}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, hashConfig.Algorithm)
+	}
+}
+
+// Hash 使用当前配置的算法生成密码哈希
+func Hash(password string) (string, error) {
+	return CurrentHasher.Hash(password)
+}
+
+// NeedsRehash 判断已有哈希是否需要按当前配置重新生成
+func NeedsRehash(encoded string) bool {
+	return CurrentHasher.NeedsRehash(encoded)
+}
+
+// Verify 校验密码与哈希是否匹配，根据哈希前缀识别 argon2id 与 bcrypt，无法识别的哈希（包括空值）一律不匹配
+func Verify(password string, encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, argon2idPrefix):
+		params, salt, key, err := decodeArgon2id(encoded)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	case isBcrypt(encoded):
+		return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil
+	default:
+		return false
+	}
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// BcryptHasher 兼容已有的 bcrypt 哈希
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h BcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcrypt(encoded) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// Argon2idHasher 生成 PHC 格式的 argon2id 哈希：$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>
+type Argon2idHasher struct {
+	params argon2Params
+}
+
+func NewArgon2idHasher(hashConfig config.PasswordHashConfig) Argon2idHasher {
+	return Argon2idHasher{params: argon2Params{
+		memory:      hashConfig.Argon2MemoryKiB(),
+		iterations:  hashConfig.Argon2Time(),
+		parallelism: hashConfig.Argon2Threads(),
+	}}
+}
+
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.iterations, h.params.memory, h.params.parallelism, argon2KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		h.params.memory, h.params.iterations, h.params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h Argon2idHasher) NeedsRehash(encoded string) bool {
+	if !strings.HasPrefix(encoded, argon2idPrefix) {
+		return true
+	}
+	params, salt, key, err := decodeArgon2id(encoded)
+	return err != nil || params != h.params || len(salt) != argon2SaltLen || len(key) != argon2KeyLen
+}
+
+func decodeArgon2id(encoded string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("argon2id 哈希格式不正确")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("不支持的 argon2 版本")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("argon2id 参数格式不正确: %w", err)
+	}
+	if params.memory == 0 || params.iterations == 0 || params.parallelism == 0 {
+		return params, nil, nil, errors.New("argon2id 参数不正确")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("argon2id 哈希值不正确")
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"go-fiber-starter/pkg/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Config 测试中使用较小的内存参数以加快速度
+var testArgon2Config = config.PasswordHashConfig{Argon2Memory: 1024, Argon2Iterations: 1}
+
+func TestArgon2idHashAndVerify(t *testing.T) {
+	t.Parallel()
+
+	hasher := NewArgon2idHasher(testArgon2Config)
+	hash, err := hasher.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("unexpected PHC string: %s", hash)
+	}
+	if !Verify("correct horse", hash) || Verify("wrong horse", hash) {
+		t.Fatalf("verify mismatch for %s", hash)
+	}
+	if hasher.NeedsRehash(hash) {
+		t.Fatalf("hash with current parameters must not need rehash")
+	}
+	if !NewArgon2idHasher(config.PasswordHashConfig{Argon2Memory: 2048, Argon2Iterations: 1}).NeedsRehash(hash) {
+		t.Fatalf("changed memory cost must trigger rehash")
+	}
+
+	other, _ := hasher.Hash("correct horse")
+	if other == hash {
+		t.Fatalf("expected random salt")
+	}
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	t.Parallel()
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("pass1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt: %v", err)
+	}
+	if !Verify("pass1234", string(legacy)) || Verify("pass12345", string(legacy)) {
+		t.Fatalf("bcrypt hashes must keep working")
+	}
+	if !NewArgon2idHasher(testArgon2Config).NeedsRehash(string(legacy)) {
+		t.Fatalf("bcrypt hash must be upgraded when argon2id is configured")
+	}
+
+	bcryptHasher, err := NewHasher(config.PasswordHashConfig{Algorithm: "bcrypt", BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatalf("new hasher: %v", err)
+	}
+	if bcryptHasher.NeedsRehash(string(legacy)) {
+		t.Fatalf("bcrypt hash with the configured cost must not need rehash")
+	}
+	if !bcryptHasher.NeedsRehash(strings.Replace(string(legacy), "$04$", "$05$", 1)) {
+		t.Fatalf("bcrypt cost change must trigger rehash")
+	}
+}
+
+func TestVerifyRejectsMalformedHashes(t *testing.T) {
+	t.Parallel()
+
+	for _, encoded := range []string{
+		"",
+		"plaintext",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$a2V5",
+	} {
+		if Verify("", encoded) {
+			t.Fatalf("malformed hash %q must not verify", encoded)
+		}
+	}
+	if _, err := NewHasher(config.PasswordHashConfig{Algorithm: "md5"}); err == nil {
+		t.Fatalf("expected unsupported algorithm error")
+	}
+}
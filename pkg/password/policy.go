@@ -0,0 +1,176 @@
+// Package password 负责密码哈希与校验，以及按配置的密码策略和离线泄露密码库检查新密码
+package password
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go-fiber-starter/pkg/config"
+	"go-fiber-starter/pkg/i18n"
+)
+
+// 违反的规则标识，前端可据此做本地化提示
+const (
+	RuleMinLength = "min_length"
+	RuleMaxLength = "max_length"
+	RuleUpper     = "upper"
+	RuleLower     = "lower"
+	RuleDigit     = "digit"
+	RuleSymbol    = "symbol"
+	RuleUsername  = "username"
+	RuleBreached  = "breached"
+)
+
+// minUsernameLength 过短的用户名不参与包含检查，避免误伤正常密码
+const minUsernameLength = 3
+
+// Violation 一条未满足的密码规则，Message 按默认语言生成，Violations.Localize 可以换成其他语言
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	// params 用于按其他语言重新生成 Message，语言包中的 key 为 password.<rule>
+	params i18n.Params
+}
+
+func newViolation(rule string, params i18n.Params) Violation {
+	violation := Violation{Rule: rule, params: params}
+	violation.Message = violation.render(i18n.Current.Default())
+	return violation
+}
+
+func (v Violation) render(locale string) string {
+	return i18n.T(locale, "password."+v.Rule, v.params)
+}
+
+// Violations 密码不符合策略时返回的错误，包含全部未满足的规则
+type Violations []Violation
+
+// Localize 返回按 locale 重新生成提示的副本
+func (v Violations) Localize(locale string) Violations {
+	localized := make(Violations, len(v))
+	for i, violation := range v {
+		violation.Message = violation.render(locale)
+		localized[i] = violation
+	}
+	return localized
+}
+
+func (v Violations) Error() string {
+	return strings.Join(v.Messages(), "；")
+}
+
+func (v Violations) Messages() []string {
+	messages := make([]string, len(v))
+	for i, violation := range v {
+		messages[i] = violation.Message
+	}
+	return messages
+}
+
+// Policy 密码策略，Breached 为空时不检查泄露密码库
+type Policy struct {
+	Config   config.PasswordPolicyConfig
+	Breached *BreachedList
+}
+
+// Current 未调用 Init 时使用默认策略
+var Current = &Policy{}
+
+func Init() error {
+	hasher, err := NewHasher(config.Current.Security.PasswordHash)
+	if err != nil {
+		return err
+	}
+	policy, err := Load(config.Current.Security.Password)
+	if err != nil {
+		return err
+	}
+	CurrentHasher = hasher
+
+	previous := Current
+	Current = policy
+	if previous != nil && previous.Breached != nil {
+		_ = previous.Breached.Close()
+	}
+	return nil
+}
+
+// Load 根据配置创建密码策略，配置了泄露密码库时会打开并建立索引
+func Load(policyConfig config.PasswordPolicyConfig) (*Policy, error) {
+	policy := &Policy{Config: policyConfig}
+	if policyConfig.BreachedList != "" {
+		breached, err := OpenBreachedList(policyConfig.BreachedList)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breached = breached
+	}
+	return policy, nil
+}
+
+// Validate 使用当前策略校验密码
+func Validate(password string, username string) error {
+	return Current.Validate(password, username)
+}
+
+// Validate 校验密码，不符合策略时返回 Violations，读取泄露密码库失败时返回其他错误
+func (p *Policy) Validate(password string, username string) error {
+	var violations Violations
+
+	if minLength := p.Config.MinLen(); utf8.RuneCountInString(password) < minLength {
+		violations = append(violations, newViolation(RuleMinLength, i18n.Params{"count": minLength}))
+	}
+	if maxLength := p.Config.MaxLen(); len(password) > maxLength {
+		violations = append(violations, newViolation(RuleMaxLength, i18n.Params{"count": maxLength}))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.Config.RequireUpper && !hasUpper {
+		violations = append(violations, newViolation(RuleUpper, nil))
+	}
+	if p.Config.RequireLower && !hasLower {
+		violations = append(violations, newViolation(RuleLower, nil))
+	}
+	if p.Config.RequireDigit && !hasDigit {
+		violations = append(violations, newViolation(RuleDigit, nil))
+	}
+	if p.Config.RequireSymbol && !hasSymbol {
+		violations = append(violations, newViolation(RuleSymbol, nil))
+	}
+
+	username = strings.TrimSpace(username)
+	if p.Config.DisallowUsername && utf8.RuneCountInString(username) >= minUsernameLength &&
+		strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violations = append(violations, newViolation(RuleUsername, nil))
+	}
+
+	// 其他规则已不满足时无需再查泄露密码库
+	if len(violations) == 0 && p.Breached != nil {
+		breached, err := p.Breached.Contains(password)
+		if err != nil {
+			return err
+		}
+		if breached {
+			violations = append(violations, newViolation(RuleBreached, nil))
+		}
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
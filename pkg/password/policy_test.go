@@ -0,0 +1,117 @@
+package password
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go-fiber-starter/pkg/config"
+)
+
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+	var violations Violations
+	if !errors.As(err, &violations) {
+		t.Fatalf("expected Violations, got %v", err)
+	}
+	rules := make([]string, len(violations))
+	for i, violation := range violations {
+		rules[i] = violation.Rule
+	}
+	return rules
+}
+
+func TestPolicyDefaults(t *testing.T) {
+	t.Parallel()
+
+	policy := &Policy{}
+	if rules := violatedRules(t, policy.Validate("", "alice")); !slices.Equal(rules, []string{RuleMinLength}) {
+		t.Fatalf("empty password rules = %v", rules)
+	}
+	if rules := violatedRules(t, policy.Validate("密码密码密码密码", "alice")); rules != nil {
+		t.Fatalf("min length must count characters, got %v", rules)
+	}
+	if rules := violatedRules(t, policy.Validate(strings.Repeat("a", 73), "alice")); !slices.Equal(rules, []string{RuleMaxLength}) {
+		t.Fatalf("73 byte password rules = %v", rules)
+	}
+}
+
+func TestPolicyCharacterClassesAndUsername(t *testing.T) {
+	t.Parallel()
+
+	policy := &Policy{Config: config.PasswordPolicyConfig{
+		MinLength:        10,
+		MaxLength:        100,
+		RequireUpper:     true,
+		RequireLower:     true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		DisallowUsername: true,
+	}}
+
+	rules := violatedRules(t, policy.Validate("alicealice", "Alice"))
+	if !slices.Equal(rules, []string{RuleUpper, RuleDigit, RuleSymbol, RuleUsername}) {
+		t.Fatalf("rules = %v", rules)
+	}
+	if rules := violatedRules(t, policy.Validate(strings.Repeat("Aa1!", 20), "alice")); !slices.Equal(rules, []string{RuleMaxLength}) {
+		t.Fatalf("max length above 72 must be capped, got %v", rules)
+	}
+	if err := policy.Validate("Correct-Horse-9", "al"); err != nil {
+		t.Fatalf("expected valid password, got %v", err)
+	}
+}
+
+func sha1Hex(value string) string {
+	sum := sha1.Sum([]byte(value))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func writeBreachedList(t *testing.T, lines ...string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\r\n")), 0o600); err != nil {
+		t.Fatalf("write list: %v", err)
+	}
+	return path
+}
+
+func TestBreachedList(t *testing.T) {
+	t.Parallel()
+
+	hashes := []string{sha1Hex("password1"), sha1Hex("12345678"), strings.ToLower(sha1Hex("qwertyuiop")) + ":42"}
+	slices.SortFunc(hashes, func(a, b string) int { return strings.Compare(strings.ToUpper(a), strings.ToUpper(b)) })
+
+	policy, err := Load(config.PasswordPolicyConfig{BreachedList: writeBreachedList(t, hashes...)})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t.Cleanup(func() { _ = policy.Breached.Close() })
+
+	for _, breached := range []string{"password1", "12345678", "qwertyuiop"} {
+		if rules := violatedRules(t, policy.Validate(breached, "")); !slices.Equal(rules, []string{RuleBreached}) {
+			t.Fatalf("%q rules = %v", breached, rules)
+		}
+	}
+	if err := policy.Validate("not-in-the-list", ""); err != nil {
+		t.Fatalf("expected password to pass, got %v", err)
+	}
+}
+
+func TestBreachedListRejectsUnsortedFile(t *testing.T) {
+	t.Parallel()
+
+	path := writeBreachedList(t, strings.Repeat("F", 40), strings.Repeat("0", 40))
+	if _, err := OpenBreachedList(path); err == nil {
+		t.Fatalf("expected unsorted list to be rejected")
+	}
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cursor 游标内容：最后一条记录各排序列的值，以及生成游标时的排序方式，排序改变后旧游标失效
+type cursor struct {
+	Sort   string        `json:"s"`
+	Values []interface{} `json:"v"`
+}
+
+func encodeCursor(tx *gorm.DB, row reflect.Value, params Params) (string, error) {
+	if tx.Statement.Schema == nil {
+		return "", errors.New("query: cursor pagination requires a model")
+	}
+	values := make([]interface{}, len(params.sort))
+	for i, column := range params.sort {
+		field := tx.Statement.Schema.LookUpField(column.column)
+		if field == nil {
+			return "", fmt.Errorf("query: column %s not found in %s", column.column, tx.Statement.Schema.Name)
+		}
+		values[i], _ = field.ValueOf(tx.Statement.Context, reflect.Indirect(row))
+	}
+
+	raw, err := json.Marshal(cursor{Sort: params.sortKey, Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(raw string, sortKey string, columns []sortColumn) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded cursor
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	if decoded.Sort != sortKey || len(decoded.Values) != len(columns) {
+		return nil, errors.New("cursor does not match sort")
+	}
+
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		value, err := cursorValue(column.kind, decoded.Values[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// cursorValue 还原 JSON 解码后的游标值，时间恢复为 time.Time，数字保持整数精度
+func cursorValue(kind Kind, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, errors.New("cursor value is null")
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case string:
+		if kind == Time {
+			return time.Parse(time.RFC3339Nano, v)
+		}
+		return v, nil
+	case bool:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported cursor value %T", value)
+	}
+}
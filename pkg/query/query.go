@@ -0,0 +1,420 @@
+// Package query 解析列表接口的分页、排序与过滤参数，并转换为 GORM scope。
+//
+// 支持两种分页方式：页码分页 page/pageSize，以及游标分页 after/limit（after 为上一页返回的 nextCursor）。
+// 排序参数形如 sort=-createdAt,username，- 表示倒序；过滤参数形如 filter[username][like]=adm，省略操作符时为 eq。
+// 只有 Spec 中声明的字段可以排序和过滤，SQL 中的列名全部来自 Spec，请求中的字段名不会进入 SQL，取值一律作为参数绑定。
+package query
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-fiber-starter/pkg/apperror"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Op 过滤操作符
+type Op string
+
+const (
+	OpEq   Op = "eq"
+	OpNe   Op = "ne"
+	OpLt   Op = "lt"
+	OpLte  Op = "lte"
+	OpGt   Op = "gt"
+	OpGte  Op = "gte"
+	OpLike Op = "like"
+	// OpIn 取值以逗号分隔
+	OpIn Op = "in"
+)
+
+// Kind 字段类型，决定过滤值和游标值的解析方式
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+	Bool
+	// Time 取值为 RFC 3339 格式
+	Time
+)
+
+// Field 允许排序或过滤的字段
+type Field struct {
+	Column string
+	Kind   Kind
+	// Sortable 是否允许排序；游标分页按排序列比较大小，可为 NULL 的列不要设为可排序
+	Sortable bool
+	// Ops 允许的过滤操作符，为空表示不能过滤
+	Ops []Op
+}
+
+// Spec 列表接口的查询规则，Fields 的 key 为请求中使用的字段名
+type Spec struct {
+	Fields map[string]Field
+	// DefaultSort 未传 sort 时使用，格式与 sort 参数相同
+	DefaultSort string
+	// KeyColumn 唯一列，追加在排序的最后保证顺序稳定，游标分页依赖它，默认 id
+	KeyColumn string
+	// DefaultPageSize 默认 20，MaxPageSize 默认 100
+	DefaultPageSize int
+	MaxPageSize     int
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	defaultKey      = "id"
+)
+
+var (
+	errInvalidSort   = apperror.Validation("invalid_sort", "query.invalid_sort")
+	errInvalidFilter = apperror.Validation("invalid_filter", "query.invalid_filter")
+	errInvalidValue  = apperror.Validation("invalid_filter_value", "query.invalid_value")
+	errInvalidCursor = apperror.Validation("invalid_cursor", "query.invalid_cursor")
+	errMixedPaging   = apperror.Validation("invalid_pagination", "query.mixed_pagination")
+)
+
+var filterPattern = regexp.MustCompile(`^filter\[([A-Za-z0-9_.]+)\](?:\[([a-z]+)\])?$`)
+
+type sortColumn struct {
+	name   string
+	column string
+	kind   Kind
+	desc   bool
+}
+
+type condition struct {
+	column string
+	op     Op
+	value  interface{}
+}
+
+// Params 校验后的查询参数，只能由 Parse 生成
+type Params struct {
+	// Page 与 PageSize 用于页码分页，Cursor 为 true 时使用 Limit 与 after 游标
+	Page     int
+	PageSize int
+	Cursor   bool
+	Limit    int
+
+	sort       []sortColumn
+	sortKey    string
+	conditions []condition
+	after      []interface{}
+}
+
+// Parse 按 spec 校验查询参数，values 通常为 c.Queries()；不在白名单内的字段和操作符返回 400 错误
+func Parse(values map[string]string, spec Spec) (Params, error) {
+	var params Params
+	if err := params.parseSort(values["sort"], spec); err != nil {
+		return Params{}, err
+	}
+	if err := params.parseFilters(values, spec); err != nil {
+		return Params{}, err
+	}
+	if err := params.parsePaging(values, spec); err != nil {
+		return Params{}, err
+	}
+	return params, nil
+}
+
+func (p *Params) parseSort(raw string, spec Spec) error {
+	if strings.TrimSpace(raw) == "" {
+		raw = spec.DefaultSort
+	}
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name := strings.TrimPrefix(item, "-")
+		field, ok := spec.Fields[name]
+		if !ok || !field.Sortable {
+			return errInvalidSort.WithParams(map[string]interface{}{"field": name})
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		p.sort = append(p.sort, sortColumn{name: name, column: field.Column, kind: field.Kind, desc: strings.HasPrefix(item, "-")})
+	}
+
+	key := spec.KeyColumn
+	if key == "" {
+		key = defaultKey
+	}
+	names := make([]string, 0, len(p.sort))
+	for _, column := range p.sort {
+		names = append(names, column.signature())
+	}
+	p.sortKey = strings.Join(names, ",")
+	// 唯一列作为最后的排序条件，相同排序值的记录在多次查询之间保持同样的顺序
+	p.sort = append(p.sort, sortColumn{name: key, column: key, kind: String})
+	return nil
+}
+
+func (s sortColumn) signature() string {
+	if s.desc {
+		return "-" + s.name
+	}
+	return s.name
+}
+
+func (p *Params) parseFilters(values map[string]string, spec Spec) error {
+	for key, raw := range values {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+		match := filterPattern.FindStringSubmatch(key)
+		if match == nil {
+			return errInvalidFilter.WithParams(map[string]interface{}{"field": key})
+		}
+		name, op := match[1], Op(match[2])
+		if op == "" {
+			op = OpEq
+		}
+		field, ok := spec.Fields[name]
+		if !ok || !allowsOp(field, op) {
+			return errInvalidFilter.WithParams(map[string]interface{}{"field": name + "[" + string(op) + "]"})
+		}
+
+		var value interface{}
+		if op == OpIn {
+			items := strings.Split(raw, ",")
+			converted := make([]interface{}, 0, len(items))
+			for _, item := range items {
+				parsed, err := parseValue(field.Kind, strings.TrimSpace(item))
+				if err != nil {
+					return errInvalidValue.WithParams(map[string]interface{}{"field": name}).Wrap(err)
+				}
+				converted = append(converted, parsed)
+			}
+			value = converted
+		} else if op == OpLike {
+			value = "%" + escapeLike(raw) + "%"
+		} else {
+			parsed, err := parseValue(field.Kind, raw)
+			if err != nil {
+				return errInvalidValue.WithParams(map[string]interface{}{"field": name}).Wrap(err)
+			}
+			value = parsed
+		}
+		p.conditions = append(p.conditions, condition{column: field.Column, op: op, value: value})
+	}
+	return nil
+}
+
+func allowsOp(field Field, op Op) bool {
+	for _, allowed := range field.Ops {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Params) parsePaging(values map[string]string, spec Spec) error {
+	pageSizeLimit := spec.MaxPageSize
+	if pageSizeLimit <= 0 {
+		pageSizeLimit = maxPageSize
+	}
+	size := spec.DefaultPageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	after, hasAfter := values["after"]
+	_, hasLimit := values["limit"]
+	_, hasPage := values["page"]
+	if (hasAfter || hasLimit) && hasPage {
+		return errMixedPaging
+	}
+
+	if hasAfter || hasLimit {
+		p.Cursor = true
+		p.Limit = pageSizeFrom(values["limit"], size, pageSizeLimit)
+		if after != "" {
+			cursorValues, err := decodeCursor(after, p.sortKey, p.sort)
+			if err != nil {
+				return errInvalidCursor.Wrap(err)
+			}
+			p.after = cursorValues
+		}
+		return nil
+	}
+
+	p.Page, _ = strconv.Atoi(values["page"])
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	p.PageSize = pageSizeFrom(values["pageSize"], size, pageSizeLimit)
+	return nil
+}
+
+// pageSizeFrom 与原有列表接口一致，超出范围时使用默认值
+func pageSizeFrom(raw string, fallback int, limit int) int {
+	size, err := strconv.Atoi(raw)
+	if err != nil || size < 1 || size > limit {
+		return fallback
+	}
+	return size
+}
+
+func parseValue(kind Kind, raw string) (interface{}, error) {
+	switch kind {
+	case Number:
+		return strconv.ParseFloat(raw, 64)
+	case Bool:
+		return strconv.ParseBool(raw)
+	case Time:
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
+
+// escapeLike 转义 LIKE 通配符，使用 ! 作为转义字符以兼容各数据库
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+// Contains 列中包含 value 的条件，value 中的 % 和 _ 按字面匹配；供 filter 参数之外的搜索条件使用
+func Contains(column string, value string) clause.Expression {
+	return like(column, "%"+escapeLike(value)+"%")
+}
+
+// HasPrefix 列以 value 开头的条件，value 中的 % 和 _ 按字面匹配
+func HasPrefix(column string, value string) clause.Expression {
+	return like(column, escapeLike(value)+"%")
+}
+
+// like pattern 中的用户输入须已经过 escapeLike 转义
+func like(column string, pattern string) clause.Expression {
+	return clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []interface{}{clause.Column{Name: column}, pattern}}
+}
+
+// FilterScope 过滤条件
+func (p Params) FilterScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, cond := range p.conditions {
+			db = db.Where(cond.expression())
+		}
+		return db
+	}
+}
+
+func (c condition) expression() clause.Expression {
+	column := clause.Column{Name: c.column}
+	switch c.op {
+	case OpNe:
+		return clause.Neq{Column: column, Value: c.value}
+	case OpLt:
+		return clause.Lt{Column: column, Value: c.value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: c.value}
+	case OpGt:
+		return clause.Gt{Column: column, Value: c.value}
+	case OpGte:
+		return clause.Gte{Column: column, Value: c.value}
+	case OpLike:
+		return like(c.column, c.value.(string))
+	case OpIn:
+		return clause.IN{Column: column, Values: c.value.([]interface{})}
+	default:
+		return clause.Eq{Column: column, Value: c.value}
+	}
+}
+
+// OrderScope 排序，最后一列总是唯一列
+func (p Params) OrderScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, column := range p.sort {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: column.column}, Desc: column.desc})
+		}
+		return db
+	}
+}
+
+// PageScope 分页：页码分页为 offset/limit；游标分页从游标之后开始，并多取一条用于判断是否还有下一页
+func (p Params) PageScope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !p.Cursor {
+			return db.Offset((p.Page - 1) * p.PageSize).Limit(p.PageSize)
+		}
+		if len(p.after) > 0 {
+			db = db.Where(p.keyset())
+		}
+		return db.Limit(p.Limit + 1)
+	}
+}
+
+// keyset 取排序在游标之后的记录：(a > va) OR (a = va AND b > vb) OR ...，倒序的列使用 <
+func (p Params) keyset() clause.Expression {
+	branches := make([]clause.Expression, 0, len(p.sort))
+	for i, column := range p.sort {
+		parts := make([]clause.Expression, 0, i+1)
+		for j := range i {
+			parts = append(parts, clause.Eq{Column: clause.Column{Name: p.sort[j].column}, Value: p.after[j]})
+		}
+		target := clause.Column{Name: column.column}
+		if column.desc {
+			parts = append(parts, clause.Lt{Column: target, Value: p.after[i]})
+		} else {
+			parts = append(parts, clause.Gt{Column: target, Value: p.after[i]})
+		}
+		branches = append(branches, clause.And(parts...))
+	}
+	return clause.Or(branches...)
+}
+
+// PageInfo 分页信息，用于生成列表响应
+type PageInfo struct {
+	Total      int64
+	Page       int
+	PageSize   int
+	Limit      int
+	NextCursor string
+	HasMore    bool
+}
+
+// Find 按 params 查询一页数据写入 dest（指向切片的指针）并统计总数；db 需要已指定 Model，其上的条件会保留，
+// scopes 只作用于数据查询，例如 Preload，不影响计数
+func Find(db *gorm.DB, params Params, dest interface{}, scopes ...func(*gorm.DB) *gorm.DB) (PageInfo, error) {
+	filtered := db.Scopes(params.FilterScope())
+
+	info := PageInfo{Page: params.Page, PageSize: params.PageSize, Limit: params.Limit}
+	if err := filtered.Session(&gorm.Session{}).Count(&info.Total).Error; err != nil {
+		return PageInfo{}, err
+	}
+
+	tx := filtered.Session(&gorm.Session{}).Scopes(scopes...).Scopes(params.OrderScope(), params.PageScope()).Find(dest)
+	if tx.Error != nil {
+		return PageInfo{}, tx.Error
+	}
+
+	if !params.Cursor {
+		info.HasMore = int64(params.Page*params.PageSize) < info.Total
+		return info, nil
+	}
+
+	items := reflect.ValueOf(dest).Elem()
+	if items.Len() > params.Limit {
+		items.Set(items.Slice(0, params.Limit))
+		info.HasMore = true
+	}
+	if info.HasMore {
+		cursor, err := encodeCursor(tx, items.Index(items.Len()-1), params)
+		if err != nil {
+			return PageInfo{}, err
+		}
+		info.NextCursor = cursor
+	}
+	return info, nil
+}
@@ -0,0 +1,205 @@
+package query
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-starter/pkg/apperror"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+type item struct {
+	Id        int64 `gorm:"primaryKey"`
+	Name      string
+	Score     int
+	CreatedAt time.Time
+}
+
+var itemSpec = Spec{
+	Fields: map[string]Field{
+		"name":      {Column: "name", Sortable: true, Ops: []Op{OpEq, OpLike, OpIn}},
+		"score":     {Column: "score", Kind: Number, Sortable: true, Ops: []Op{OpGte, OpLt}},
+		"createdAt": {Column: "created_at", Kind: Time, Sortable: true},
+	},
+	DefaultSort: "-createdAt",
+}
+
+func openItems(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "query.sqlite")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []item{
+		{Id: 1, Name: "alice", Score: 3, CreatedAt: base},
+		{Id: 2, Name: "bob", Score: 5, CreatedAt: base.Add(time.Hour)},
+		{Id: 3, Name: "carol", Score: 3, CreatedAt: base.Add(2 * time.Hour)},
+		{Id: 4, Name: "100%_dave", Score: 5, CreatedAt: base.Add(3 * time.Hour)},
+		{Id: 5, Name: "erin", Score: 3, CreatedAt: base.Add(3 * time.Hour)},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return db
+}
+
+func names(items []item) string {
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = it.Name
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestParseRejectsUnknownFields(t *testing.T) {
+	cases := []struct {
+		values map[string]string
+		code   string
+	}{
+		{map[string]string{"sort": "password"}, "invalid_sort"},
+		{map[string]string{"sort": "name;DROP TABLE items"}, "invalid_sort"},
+		{map[string]string{"sort": "id"}, "invalid_sort"},
+		{map[string]string{"filter[password]": "x"}, "invalid_filter"},
+		{map[string]string{"filter[name][gt]": "a"}, "invalid_filter"},
+		{map[string]string{"filter[name) OR 1=1 --]": "a"}, "invalid_filter"},
+		{map[string]string{"filter[createdAt]": "2025-01-01T00:00:00Z"}, "invalid_filter"},
+		{map[string]string{"filter[score][gte]": "high"}, "invalid_filter_value"},
+		{map[string]string{"page": "2", "limit": "10"}, "invalid_pagination"},
+		{map[string]string{"after": "not-a-cursor"}, "invalid_cursor"},
+	}
+	for _, tc := range cases {
+		_, err := Parse(tc.values, itemSpec)
+		appErr, ok := apperror.As(err)
+		if !ok || appErr.Code != tc.code || appErr.Status != 400 {
+			t.Fatalf("Parse(%v) error = %v, want %s", tc.values, err, tc.code)
+		}
+	}
+}
+
+func TestFindWithPageFilterAndSort(t *testing.T) {
+	db := openItems(t)
+
+	params, err := Parse(map[string]string{"sort": "-score,name", "page": "1", "pageSize": "3"}, itemSpec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var page []item
+	info, err := Find(db.Model(&item{}), params, &page)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if got := names(page); got != "100%_dave,bob,alice" || info.Total != 5 || !info.HasMore {
+		t.Fatalf("unexpected page %s total=%d hasMore=%v", got, info.Total, info.HasMore)
+	}
+
+	params, err = Parse(map[string]string{"filter[name][like]": "%_", "filter[score][gte]": "4"}, itemSpec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var filtered []item
+	if info, err = Find(db.Model(&item{}), params, &filtered); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	// 通配符按字面匹配
+	if got := names(filtered); got != "100%_dave" || info.Total != 1 || info.HasMore {
+		t.Fatalf("unexpected filter result %s total=%d", got, info.Total)
+	}
+
+	params, err = Parse(map[string]string{"filter[name][in]": "bob,erin", "sort": "name"}, itemSpec)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var in []item
+	if _, err = Find(db.Model(&item{}), params, &in); err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if got := names(in); got != "bob,erin" {
+		t.Fatalf("unexpected in result %s", got)
+	}
+}
+
+func TestFindWithCursor(t *testing.T) {
+	db := openItems(t)
+
+	for _, sort := range []string{"-score,name", "", "score,-createdAt"} {
+		var all, want []item
+		if err := db.Scopes(mustParse(t, map[string]string{"sort": sort}).OrderScope()).Find(&want).Error; err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+
+		values := map[string]string{"sort": sort, "limit": "2"}
+		for range len(want) {
+			params := mustParse(t, values)
+			var page []item
+			info, err := Find(db.Model(&item{}), params, &page)
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			if info.Total != 5 || info.Limit != 2 {
+				t.Fatalf("unexpected page info %+v", info)
+			}
+			all = append(all, page...)
+			if !info.HasMore {
+				break
+			}
+			values["after"] = info.NextCursor
+		}
+		if names(all) != names(want) {
+			t.Fatalf("sort %q: cursor pages %s, want %s", sort, names(all), names(want))
+		}
+	}
+
+	// 排序方式改变后旧游标失效
+	params := mustParse(t, map[string]string{"sort": "name", "limit": "1"})
+	var page []item
+	info, err := Find(db.Model(&item{}), params, &page)
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	_, err = Parse(map[string]string{"sort": "-name", "after": info.NextCursor}, itemSpec)
+	if appErr, ok := apperror.As(err); !ok || !errors.Is(appErr, errInvalidCursor) {
+		t.Fatalf("expected invalid cursor, got %v", err)
+	}
+}
+
+func mustParse(t *testing.T, values map[string]string) Params {
+	t.Helper()
+	params, err := Parse(values, itemSpec)
+	if err != nil {
+		t.Fatalf("Parse(%v): %v", values, err)
+	}
+	return params
+}
+
+func TestContainsAndHasPrefixEscapeWildcards(t *testing.T) {
+	db := openItems(t)
+
+	cases := []struct {
+		scope *gorm.DB
+		want  string
+	}{
+		{db.Where(Contains("name", "%_")), "100%_dave"},
+		{db.Where(Contains("name", "_")), "100%_dave"},
+		{db.Where(Contains("name", "ro")), "carol"},
+		{db.Where(HasPrefix("name", "1_0")), ""},
+		{db.Where(HasPrefix("name", "100%")), "100%_dave"},
+	}
+	for i, tc := range cases {
+		var found []item
+		if err := tc.scope.Order("id").Find(&found).Error; err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if got := names(found); got != tc.want {
+			t.Fatalf("case %d: got %q, want %q", i, got, tc.want)
+		}
+	}
+}
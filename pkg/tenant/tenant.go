@@ -0,0 +1,48 @@
+// Package tenant 在 context 中传递当前请求所属的组织，数据库层据此自动限定多租户数据的查询范围
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Column 多租户表中保存组织 Id 的列名
+const Column = "organization_id"
+
+type tenantKey struct{}
+
+type crossTenantKey struct{}
+
+// Scoped 多租户模型的标记接口，嵌入 base.TenantModel 的模型自动实现
+type Scoped interface {
+	TenantScoped()
+}
+
+// WithTenant 返回携带组织 Id 的 context
+func WithTenant(ctx context.Context, organizationId uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, organizationId)
+}
+
+// FromContext 读取 context 中的组织 Id，未设置时返回 false
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	organizationId, ok := ctx.Value(tenantKey{}).(uuid.UUID)
+	return organizationId, ok && organizationId != uuid.Nil
+}
+
+// WithCrossTenant 显式允许跨租户访问，只应在管理后台、后台任务等确需访问全部组织数据的地方使用
+func WithCrossTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, crossTenantKey{}, true)
+}
+
+// IsCrossTenant 是否显式允许跨租户访问
+func IsCrossTenant(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	allowed, _ := ctx.Value(crossTenantKey{}).(bool)
+	return allowed
+}
@@ -0,0 +1,52 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore 进程内存储，适合单实例部署
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+	// retention 记录在最后一次失败且锁定结束后保留的时间
+	retention time.Duration
+}
+
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{entries: make(map[string]Entry), retention: retention}
+}
+
+func (s *MemoryStore) Get(key string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	return entry, ok, nil
+}
+
+func (s *MemoryStore) Save(key string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purge(time.Now())
+	s.entries[key] = entry
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// purge 清理过期记录，调用方需持有锁
+func (s *MemoryStore) purge(now time.Time) {
+	for key, entry := range s.entries {
+		if now.After(entry.LockedUntil) && now.Sub(entry.LastFailureAt) > s.retention {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Package throttle 记录失败次数，超过阈值后按指数退避临时锁定，用于登录等需要防暴力破解的场景
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Policy 锁定策略：窗口期内失败 Threshold 次后锁定 BaseLockout，之后每多失败一次锁定时间翻倍，最长 MaxLockout
+type Policy struct {
+	Threshold   int
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+// Entry 单个 key 的失败记录
+type Entry struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store 失败记录存储，单实例可用内存存储，多实例部署需使用共享存储（如数据库）
+type Store interface {
+	Get(key string) (Entry, bool, error)
+	Save(key string, entry Entry) error
+	Delete(key string) error
+}
+
+// Limiter 按策略记录失败并判断是否锁定
+type Limiter struct {
+	store  Store
+	policy Policy
+	// mu 保证同一进程内读改写的原子性，跨实例的并发以存储为准
+	mu sync.Mutex
+}
+
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy}
+}
+
+// RetryAfter 返回 key 剩余的锁定时间，未锁定时为 0
+func (l *Limiter) RetryAfter(key string, now time.Time) (time.Duration, error) {
+	entry, ok, err := l.store.Get(key)
+	if err != nil || !ok {
+		return 0, err
+	}
+	if now.Before(entry.LockedUntil) {
+		return entry.LockedUntil.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// Fail 记录一次失败，返回更新后的记录；本次失败触发锁定时 LockedUntil 晚于 now
+func (l *Limiter) Fail(key string, now time.Time) (Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, _, err := l.store.Get(key)
+	if err != nil {
+		return Entry{}, err
+	}
+	entry = Next(entry, l.policy, now)
+	if err := l.store.Save(key, entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Reset 清除 key 的失败记录
+func (l *Limiter) Reset(key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.store.Delete(key)
+}
+
+// Next 计算记录一次失败后的状态，超过窗口期未再失败时重新计数
+func Next(entry Entry, policy Policy, now time.Time) Entry {
+	if !entry.LastFailureAt.IsZero() && now.Sub(entry.LastFailureAt) > policy.Window && !now.Before(entry.LockedUntil) {
+		entry = Entry{}
+	}
+
+	entry.Failures++
+	entry.LastFailureAt = now
+	if policy.Threshold > 0 && entry.Failures >= policy.Threshold {
+		entry.LockedUntil = now.Add(Lockout(entry.Failures, policy))
+	}
+	return entry
+}
+
+// Lockout 第 failures 次失败对应的锁定时长
+func Lockout(failures int, policy Policy) time.Duration {
+	lockout := policy.BaseLockout
+	for i := policy.Threshold; i < failures; i++ {
+		lockout *= 2
+		if policy.MaxLockout > 0 && lockout >= policy.MaxLockout {
+			return policy.MaxLockout
+		}
+	}
+	if policy.MaxLockout > 0 && lockout > policy.MaxLockout {
+		return policy.MaxLockout
+	}
+	return lockout
+}
@@ -0,0 +1,82 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{
+	Threshold:   3,
+	Window:      10 * time.Minute,
+	BaseLockout: time.Minute,
+	MaxLockout:  5 * time.Minute,
+}
+
+func TestLimiterLocksAfterThreshold(t *testing.T) {
+	t.Parallel()
+
+	limiter := NewLimiter(NewMemoryStore(time.Hour), testPolicy)
+	now := time.Unix(1700000000, 0)
+
+	for i := 0; i < 2; i++ {
+		entry, err := limiter.Fail("alice", now)
+		if err != nil {
+			t.Fatalf("Fail returned error: %v", err)
+		}
+		if now.Before(entry.LockedUntil) {
+			t.Fatalf("locked before reaching threshold")
+		}
+	}
+
+	entry, _ := limiter.Fail("alice", now)
+	if entry.LockedUntil != now.Add(time.Minute) {
+		t.Fatalf("expected 1m lockout, got until %v", entry.LockedUntil)
+	}
+	retryAfter, _ := limiter.RetryAfter("alice", now.Add(30*time.Second))
+	if retryAfter != 30*time.Second {
+		t.Fatalf("retry after %v != 30s", retryAfter)
+	}
+	if retryAfter, _ := limiter.RetryAfter("bob", now); retryAfter != 0 {
+		t.Fatalf("unrelated key must not be locked")
+	}
+
+	if err := limiter.Reset("alice"); err != nil {
+		t.Fatalf("Reset returned error: %v", err)
+	}
+	if retryAfter, _ := limiter.RetryAfter("alice", now); retryAfter != 0 {
+		t.Fatalf("expected reset key to be unlocked")
+	}
+}
+
+func TestLockoutBackoffIsCapped(t *testing.T) {
+	t.Parallel()
+
+	expected := map[int]time.Duration{
+		3: time.Minute,
+		4: 2 * time.Minute,
+		5: 4 * time.Minute,
+		6: 5 * time.Minute,
+		9: 5 * time.Minute,
+	}
+	for failures, lockout := range expected {
+		if got := Lockout(failures, testPolicy); got != lockout {
+			t.Fatalf("failures %d: lockout %v != %v", failures, got, lockout)
+		}
+	}
+}
+
+func TestNextResetsAfterWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1700000000, 0)
+	entry := Next(Entry{}, testPolicy, now)
+	entry = Next(entry, testPolicy, now.Add(time.Minute))
+	if entry.Failures != 2 {
+		t.Fatalf("failures %d != 2", entry.Failures)
+	}
+
+	entry = Next(entry, testPolicy, now.Add(time.Hour))
+	if entry.Failures != 1 {
+		t.Fatalf("expected counter to restart after window, got %d", entry.Failures)
+	}
+}
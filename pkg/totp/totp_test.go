@@ -0,0 +1,67 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 中 SHA1 的测试向量（取后 6 位）
+func TestCodeAtRFC6238Vectors(t *testing.T) {
+	t.Parallel()
+
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range cases {
+		code, err := CodeAt(secret, Step(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("CodeAt returned error: %v", err)
+		}
+		if code != expected {
+			t.Fatalf("time %d: code %s != %s", unix, code, expected)
+		}
+	}
+}
+
+func TestValidateWithSkew(t *testing.T) {
+	t.Parallel()
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret returned error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	previous, _ := CodeAt(secret, Step(now)-1)
+
+	step, ok := Validate(secret, previous, now, 1)
+	if !ok || step != Step(now)-1 {
+		t.Fatalf("expected previous step code to validate")
+	}
+	if _, ok := Validate(secret, previous, now, 0); ok {
+		t.Fatalf("expected previous step code to fail without skew")
+	}
+	if _, ok := Validate(secret, "12345", now, 1); ok {
+		t.Fatalf("expected short code to fail")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	t.Parallel()
+
+	uri := ProvisioningURI("Go Fiber", "alice", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/Go%20Fiber:alice?") {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+	for _, part := range []string{"secret=ABC", "issuer=Go+Fiber", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Fatalf("uri %s missing %s", uri, part)
+		}
+	}
+}
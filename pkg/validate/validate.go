@@ -0,0 +1,324 @@
+// Package validate 按 struct tag 校验请求参数，实现 fiber.StructValidator，挂到 fiber.Config 后 c.Bind() 绑定完成即自动校验。
+//
+// 规则写在 validate tag 中，以逗号分隔，例如 `validate:"required,min=3,max=32"`；regex 的参数可能包含逗号，必须放在最后。
+// 字段名取 json tag，错误提示中的字段名取 label tag，未设置时使用字段名。
+// 错误提示与 label 都可以是语言包中的 key，Message 按默认语言生成，Errors.Localize 可以换成其他语言。
+// 除 required 外，其余规则在字段为零值时跳过，可选字段无需额外声明。
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go-fiber-starter/pkg/i18n"
+)
+
+// FieldError 单个字段未通过的规则
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+
+	// key、label 与 param 用于按其他语言重新生成 Message
+	key   string
+	label string
+}
+
+// Errors 校验失败时返回，包含全部未通过的字段
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Message
+	}
+	return strings.Join(messages, "；")
+}
+
+// Localize 返回按 locale 重新生成提示的副本
+func (e Errors) Localize(locale string) Errors {
+	localized := make(Errors, len(e))
+	for i, fieldErr := range e {
+		fieldErr.Message = fieldErr.render(locale)
+		localized[i] = fieldErr
+	}
+	return localized
+}
+
+func (e FieldError) render(locale string) string {
+	return i18n.T(locale, e.key, i18n.Params{"field": i18n.T(locale, e.label), "param": e.Param})
+}
+
+// Func 自定义校验函数，value 为字段值（指针已解引用），param 为规则中 = 之后的部分
+type Func func(value reflect.Value, param string) bool
+
+type rule struct {
+	check Func
+	// message 错误提示模板或语言包中的 key，{field} 和 {param} 会被替换
+	message string
+}
+
+// Validator 校验规则集合，零值不可用，使用 New 创建
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]rule
+}
+
+// Default 内置规则的默认实例，项目自定义的规则注册到这里
+var Default = New()
+
+// New 创建只包含内置规则的 Validator
+func New() *Validator {
+	v := &Validator{rules: map[string]rule{
+		// min/max 的提示与字段类型有关，由 boundMessage 选择
+		"min": {check: minRule},
+		"max": {check: maxRule},
+	}}
+	v.Register("email", emailRule, "validation.email")
+	v.Register("oneof", oneOfRule, "validation.oneof")
+	v.Register("regex", regexRule, "validation.regex")
+	return v
+}
+
+// Register 注册自定义规则，同名规则会被覆盖；required 由校验流程本身处理，不能覆盖。message 为空时使用通用提示
+func (v *Validator) Register(name string, check Func, message string) {
+	if message == "" {
+		message = "validation.invalid"
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rules[name] = rule{check: check, message: message}
+}
+
+// Register 向 Default 注册自定义规则
+func Register(name string, check Func, message string) {
+	Default.Register(name, check, message)
+}
+
+// Struct 使用 Default 校验结构体
+func Struct(out any) error {
+	return Default.Validate(out)
+}
+
+// Validate 实现 fiber.StructValidator，全部通过返回 nil，否则返回 Errors
+func (v *Validator) Validate(out any) error {
+	value := reflect.ValueOf(out)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	v.validateStruct(value, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (v *Validator) validateStruct(value reflect.Value, prefix string, errs *Errors) {
+	structType := value.Type()
+	for i := range structType.NumField() {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := fieldName(field)
+		if name == "-" {
+			continue
+		}
+		name = prefix + name
+		fieldValue := value.Field(i)
+
+		if tag := field.Tag.Get("validate"); tag != "" {
+			label := field.Tag.Get("label")
+			if label == "" {
+				label = field.Name
+			}
+			v.validateField(fieldValue, tag, name, label, errs)
+		}
+
+		// 嵌套结构体的字段以 parent.child 的形式返回
+		nested := fieldValue
+		for nested.Kind() == reflect.Pointer && !nested.IsNil() {
+			nested = nested.Elem()
+		}
+		if nested.Kind() == reflect.Struct && nested.Type() != timeType {
+			v.validateStruct(nested, name+".", errs)
+		}
+	}
+}
+
+func (v *Validator) validateField(value reflect.Value, tag, field, label string, errs *Errors) {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value = reflect.Value{}
+			break
+		}
+		value = value.Elem()
+	}
+	empty := !value.IsValid() || isEmpty(value)
+
+	for _, item := range splitRules(tag) {
+		name, param, _ := strings.Cut(item, "=")
+		if name == "required" {
+			if empty {
+				errs.add(FieldError{Field: field, Rule: name, key: "validation.required", label: label})
+				return
+			}
+			continue
+		}
+		if empty {
+			continue
+		}
+
+		v.mu.RLock()
+		r, ok := v.rules[name]
+		v.mu.RUnlock()
+		if !ok {
+			panic(fmt.Sprintf("validate: unknown rule %q on field %s", name, field))
+		}
+		if r.check(value, param) {
+			continue
+		}
+
+		key := r.message
+		if key == "" {
+			key = boundMessage(name, value)
+		}
+		errs.add(FieldError{Field: field, Rule: name, Param: param, key: key, label: label})
+		// 同一字段只报告第一条未通过的规则
+		return
+	}
+}
+
+// add 按默认语言生成提示后追加
+func (e *Errors) add(fieldErr FieldError) {
+	fieldErr.Message = fieldErr.render(i18n.Current.Default())
+	*e = append(*e, fieldErr)
+}
+
+// splitRules 按逗号拆分规则，regex 之后的内容整体作为其参数
+func splitRules(tag string) []string {
+	var rules []string
+	for tag != "" {
+		if strings.HasPrefix(tag, "regex=") {
+			return append(rules, tag)
+		}
+		item, rest, _ := strings.Cut(tag, ",")
+		if item = strings.TrimSpace(item); item != "" {
+			rules = append(rules, item)
+		}
+		tag = rest
+	}
+	return rules
+}
+
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// isEmpty 字符串去掉首尾空白后为空也视为未填写
+func isEmpty(value reflect.Value) bool {
+	if value.Kind() == reflect.String {
+		return strings.TrimSpace(value.String()) == ""
+	}
+	return value.IsZero()
+}
+
+// size 字符串按字符数、集合按元素个数、数值按值比较
+func size(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func compareSize(value reflect.Value, param string, ok func(actual, limit float64) bool) bool {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: invalid size parameter %q", param))
+	}
+	actual, supported := size(value)
+	return supported && ok(actual, limit)
+}
+
+func minRule(value reflect.Value, param string) bool {
+	return compareSize(value, param, func(actual, limit float64) bool { return actual >= limit })
+}
+
+func maxRule(value reflect.Value, param string) bool {
+	return compareSize(value, param, func(actual, limit float64) bool { return actual <= limit })
+}
+
+func boundMessage(name string, value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return "validation." + name + "_length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "validation." + name + "_items"
+	default:
+		return "validation." + name
+	}
+}
+
+func emailRule(value reflect.Value, _ string) bool {
+	if value.Kind() != reflect.String {
+		return false
+	}
+	email := strings.TrimSpace(value.String())
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+// oneOfRule 可选值以空格分隔，例如 oneof=asc desc
+func oneOfRule(value reflect.Value, param string) bool {
+	actual := fmt.Sprint(value.Interface())
+	for _, option := range strings.Fields(param) {
+		if actual == option {
+			return true
+		}
+	}
+	return false
+}
+
+var patterns sync.Map // 表达式 -> *regexp.Regexp
+
+func regexRule(value reflect.Value, param string) bool {
+	if value.Kind() != reflect.String {
+		return false
+	}
+	compiled, ok := patterns.Load(param)
+	if !ok {
+		compiled, _ = patterns.LoadOrStore(param, regexp.MustCompile(param))
+	}
+	return compiled.(*regexp.Regexp).MatchString(value.String())
+}
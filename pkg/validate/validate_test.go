@@ -0,0 +1,77 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type address struct {
+	City string `json:"city" validate:"required" label:"城市"`
+}
+
+type signup struct {
+	Name    string   `json:"name" validate:"required,min=2,max=4" label:"名称"`
+	Email   string   `json:"email" validate:"email"`
+	Role    string   `json:"role" validate:"oneof=admin user"`
+	Code    string   `json:"code" validate:"regex=^[A-Z]{2,3}$"`
+	Age     int      `json:"age" validate:"min=18"`
+	Tags    []string `json:"tags" validate:"max=2"`
+	Even    int      `json:"even" validate:"even"`
+	Address *address `json:"address"`
+}
+
+func TestValidateReportsEveryField(t *testing.T) {
+	v := New()
+	v.Register("even", func(value reflect.Value, _ string) bool { return value.Int()%2 == 0 }, "{field}必须是偶数")
+
+	err := v.Validate(&signup{
+		Name:    "名称过长的值",
+		Email:   "nope",
+		Role:    "root",
+		Code:    "abc",
+		Age:     10,
+		Tags:    []string{"a", "b", "c"},
+		Even:    3,
+		Address: &address{},
+	})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+
+	want := []FieldError{
+		{Field: "name", Rule: "max", Param: "4", Message: "名称长度不能超过4个字符"},
+		{Field: "email", Rule: "email", Message: "Email格式不正确"},
+		{Field: "role", Rule: "oneof", Param: "admin user", Message: "Role必须是以下值之一: admin user"},
+		{Field: "code", Rule: "regex", Param: "^[A-Z]{2,3}$", Message: "Code格式不正确"},
+		{Field: "age", Rule: "min", Param: "18", Message: "Age不能小于18"},
+		{Field: "tags", Rule: "max", Param: "2", Message: "Tags最多只能有2项"},
+		{Field: "even", Rule: "even", Message: "Even必须是偶数"},
+		{Field: "address.city", Rule: "required", Message: "城市不能为空"},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), errs)
+	}
+	for i, got := range errs {
+		if got.Field != want[i].Field || got.Rule != want[i].Rule || got.Param != want[i].Param || got.Message != want[i].Message {
+			t.Fatalf("error %d:\n got %+v\nwant %+v", i, got, want[i])
+		}
+	}
+
+	if localized := errs.Localize("en"); localized[0].Message != "名称 must be at most 4 characters" || errs[0].Message != "名称长度不能超过4个字符" {
+		t.Fatalf("unexpected localized message: %q", localized[0].Message)
+	}
+}
+
+func TestValidateSkipsEmptyOptionalFields(t *testing.T) {
+	v := New()
+	v.Register("even", func(reflect.Value, string) bool { return false }, "")
+
+	if err := v.Validate(&signup{Name: "ok"}); err != nil {
+		t.Fatalf("expected optional fields to be skipped, got %v", err)
+	}
+	if err := v.Validate(&signup{}); err == nil || err.Error() != "名称不能为空" {
+		t.Fatalf("expected required error, got %v", err)
+	}
+}